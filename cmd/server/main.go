@@ -31,27 +31,89 @@ func main() {
 	// Get database instance
 	db := database.GetDB()
 
+	// Parse tax and service charge rules
+	taxRules, err := service.NewTaxRules(cfg.Tax.Rate, cfg.Tax.Inclusive, cfg.Tax.ServiceChargeRate)
+	if err != nil {
+		log.Fatalf("Invalid tax configuration: %v", err)
+	}
+
+	// Parse manual discount limits per role
+	discountPolicy, err := service.NewDiscountPolicy(cfg.Discount.CashierLimit, cfg.Discount.SupervisorLimit, cfg.Discount.AdminLimit)
+	if err != nil {
+		log.Fatalf("Invalid discount configuration: %v", err)
+	}
+
+	// Deliver low-stock alerts to the configured webhook, or to the log
+	stockNotifier := service.NewStockAlertNotifier(cfg.Inventory.LowStockWebhookURL)
+
 	// Initialize repositories
 	userRepo := repository.NewUserRepository(db)
 	menuRepo := repository.NewMenuRepository(db)
+	categoryRepo := repository.NewCategoryRepository(db)
+	modifierRepo := repository.NewModifierRepository(db)
+	stockMovementRepo := repository.NewStockMovementRepository(db)
+	ingredientRepo := repository.NewIngredientRepository(db)
+	stockTakeRepo := repository.NewStockTakeRepository(db)
+	supplierRepo := repository.NewSupplierRepository(db)
+	purchaseOrderRepo := repository.NewPurchaseOrderRepository(db)
 	transactionRepo := repository.NewTransactionRepository(db)
+	refundRepo := repository.NewRefundRepository(db)
+	promotionRepo := repository.NewPromotionRepository(db)
 
 	// Initialize services
 	userService := service.NewUserService(userRepo, cfg.JWT.Secret)
-	menuService := service.NewMenuService(menuRepo)
-	transactionService := service.NewTransactionService(transactionRepo, menuRepo)
+	menuService := service.NewMenuService(menuRepo, categoryRepo, modifierRepo)
+	categoryService := service.NewCategoryService(categoryRepo)
+	modifierService := service.NewModifierService(modifierRepo)
+	inventoryService := service.NewInventoryService(menuRepo, stockMovementRepo, ingredientRepo, stockNotifier)
+	ingredientService := service.NewIngredientService(ingredientRepo, menuRepo, modifierRepo, stockNotifier)
+	stockTakeService := service.NewStockTakeService(stockTakeRepo, menuRepo, stockMovementRepo, ingredientRepo, stockNotifier)
+	supplierService := service.NewSupplierService(supplierRepo)
+	purchaseOrderService := service.NewPurchaseOrderService(purchaseOrderRepo, supplierRepo, menuRepo, ingredientRepo)
+	transactionService := service.NewTransactionService(transactionRepo, menuRepo, promotionRepo, modifierRepo, ingredientRepo, taxRules, discountPolicy, stockNotifier, cfg.Outlet.Code)
+	refundService := service.NewRefundService(refundRepo, transactionRepo, menuRepo, ingredientRepo, promotionRepo)
+	promotionService := service.NewPromotionService(promotionRepo, menuRepo)
+	reportService := service.NewReportService(transactionRepo, cfg.Outlet.Code)
+
+	// Create the first admin account on an empty database, or recover one when no active admin is left
+	if err := userService.BootstrapAdmin(cfg.Admin.Username, cfg.Admin.Password); err != nil {
+		log.Fatalf("Failed to bootstrap admin account: %v", err)
+	}
 
 	// Initialize handlers
 	authHandler := handler.NewAuthHandler(userService)
+	userHandler := handler.NewUserHandler(userService)
 	menuHandler := handler.NewMenuHandler(menuService)
+	categoryHandler := handler.NewCategoryHandler(categoryService)
+	modifierHandler := handler.NewModifierHandler(modifierService)
+	inventoryHandler := handler.NewInventoryHandler(inventoryService)
+	ingredientHandler := handler.NewIngredientHandler(ingredientService)
+	stockTakeHandler := handler.NewStockTakeHandler(stockTakeService)
+	supplierHandler := handler.NewSupplierHandler(supplierService)
+	purchaseOrderHandler := handler.NewPurchaseOrderHandler(purchaseOrderService)
 	transactionHandler := handler.NewTransactionHandler(transactionService)
+	refundHandler := handler.NewRefundHandler(refundService)
+	promotionHandler := handler.NewPromotionHandler(promotionService)
+	reportHandler := handler.NewReportHandler(reportService)
 
 	// Setup router with all handlers
 	r := router.SetupRouter(&router.RouterConfig{
-		AuthHandler:        authHandler,
-		MenuHandler:        menuHandler,
-		TransactionHandler: transactionHandler,
-		JWTSecret:          cfg.JWT.Secret,
+		AuthHandler:          authHandler,
+		UserHandler:          userHandler,
+		MenuHandler:          menuHandler,
+		CategoryHandler:      categoryHandler,
+		ModifierHandler:      modifierHandler,
+		InventoryHandler:     inventoryHandler,
+		IngredientHandler:    ingredientHandler,
+		StockTakeHandler:     stockTakeHandler,
+		SupplierHandler:      supplierHandler,
+		PurchaseOrderHandler: purchaseOrderHandler,
+		TransactionHandler:   transactionHandler,
+		RefundHandler:        refundHandler,
+		PromotionHandler:     promotionHandler,
+		ReportHandler:        reportHandler,
+		UserRepo:             userRepo,
+		JWTSecret:            cfg.JWT.Secret,
 	})
 
 	// Start server
@@ -9,9 +9,14 @@ import (
 
 // Config holds all configuration values for the application
 type Config struct {
-	Database DatabaseConfig
-	Server   ServerConfig
-	JWT      JWTConfig
+	Database  DatabaseConfig
+	Server    ServerConfig
+	JWT       JWTConfig
+	Admin     AdminConfig
+	Tax       TaxConfig
+	Discount  DiscountConfig
+	Inventory InventoryConfig
+	Outlet    OutletConfig
 }
 
 // DatabaseConfig holds database connection parameters
@@ -33,6 +38,42 @@ type JWTConfig struct {
 	Secret string
 }
 
+// AdminConfig holds the credentials used to bootstrap the first admin account
+type AdminConfig struct {
+	Username string
+	Password string
+}
+
+// TaxConfig holds the tax and service charge rules applied at checkout
+// Rates are percentages, e.g. "11" for 11% PPN
+type TaxConfig struct {
+	Rate              string
+	Inclusive         bool
+	ServiceChargeRate string
+}
+
+// DiscountConfig holds the largest manual discount each role may give,
+// as a percentage of the discounted amount
+type DiscountConfig struct {
+	CashierLimit    string
+	SupervisorLimit string
+	AdminLimit      string
+}
+
+// InventoryConfig holds inventory alerting settings
+// LowStockWebhookURL receives low-stock and sold-out alerts as JSON; when empty
+// alerts are only written to the log
+type InventoryConfig struct {
+	LowStockWebhookURL string
+}
+
+// OutletConfig identifies the outlet served by this deployment
+// Each deployment serves a single outlet; Code is stored on every sale it rings
+// up and is the outlet the sales summary reports on by default
+type OutletConfig struct {
+	Code string
+}
+
 // LoadConfig loads configuration from environment variables using Viper
 func LoadConfig() (*Config, error) {
 	// Set default configuration file name and type
@@ -52,6 +93,13 @@ func LoadConfig() (*Config, error) {
 	viper.SetDefault("DB_NAME", "cashier_db")
 	viper.SetDefault("JWT_SECRET", "supersecretkey")
 	viper.SetDefault("SERVER_PORT", "8080")
+	viper.SetDefault("TAX_RATE", "0")
+	viper.SetDefault("TAX_INCLUSIVE", false)
+	viper.SetDefault("SERVICE_CHARGE_RATE", "0")
+	viper.SetDefault("DISCOUNT_LIMIT_CASHIER", "10")
+	viper.SetDefault("DISCOUNT_LIMIT_SUPERVISOR", "50")
+	viper.SetDefault("DISCOUNT_LIMIT_ADMIN", "100")
+	viper.SetDefault("OUTLET_CODE", "main")
 
 	// Read configuration file (optional, will use env vars if not found)
 	if err := viper.ReadInConfig(); err != nil {
@@ -77,6 +125,26 @@ func LoadConfig() (*Config, error) {
 		JWT: JWTConfig{
 			Secret: viper.GetString("JWT_SECRET"),
 		},
+		Admin: AdminConfig{
+			Username: viper.GetString("ADMIN_USERNAME"),
+			Password: viper.GetString("ADMIN_PASSWORD"),
+		},
+		Tax: TaxConfig{
+			Rate:              viper.GetString("TAX_RATE"),
+			Inclusive:         viper.GetBool("TAX_INCLUSIVE"),
+			ServiceChargeRate: viper.GetString("SERVICE_CHARGE_RATE"),
+		},
+		Discount: DiscountConfig{
+			CashierLimit:    viper.GetString("DISCOUNT_LIMIT_CASHIER"),
+			SupervisorLimit: viper.GetString("DISCOUNT_LIMIT_SUPERVISOR"),
+			AdminLimit:      viper.GetString("DISCOUNT_LIMIT_ADMIN"),
+		},
+		Inventory: InventoryConfig{
+			LowStockWebhookURL: viper.GetString("LOW_STOCK_WEBHOOK_URL"),
+		},
+		Outlet: OutletConfig{
+			Code: viper.GetString("OUTLET_CODE"),
+		},
 	}
 
 	return config, nil
@@ -20,6 +20,8 @@ func Connect(dsn string) error {
 	// Configure GORM with logger
 	DB, err = gorm.Open(mysql.Open(dsn), &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Info),
+		// Translate driver errors such as duplicate keys into gorm.Err* values
+		TranslateError: true,
 	})
 
 	if err != nil {
@@ -42,19 +44,74 @@ func AutoMigrate() error {
 
 	err := DB.AutoMigrate(
 		&model.User{},
+		&model.Category{},
+		&model.ModifierGroup{},
+		&model.ModifierOption{},
 		&model.Menu{},
 		&model.Transaction{},
 		&model.TransactionDetail{},
+		&model.TransactionDetailModifier{},
+		&model.Payment{},
+		&model.Promotion{},
+		&model.TransactionDiscount{},
+		&model.Refund{},
+		&model.RefundItem{},
+		&model.StockMovement{},
+		&model.Ingredient{},
+		&model.MenuIngredient{},
+		&model.ModifierOptionIngredient{},
+		&model.IngredientMovement{},
+		&model.StockTake{},
+		&model.StockTakeLine{},
+		&model.Supplier{},
+		&model.PurchaseOrder{},
+		&model.PurchaseOrderLine{},
 	)
 
 	if err != nil {
 		return err
 	}
 
+	if err := backfillDetailSnapshots(); err != nil {
+		return err
+	}
+
+	if err := backfillTaxBreakdown(); err != nil {
+		return err
+	}
+
 	log.Println("Database migrations completed successfully")
 	return nil
 }
 
+// backfillDetailSnapshots fills the menu name and unit price snapshots on
+// transaction details created before those columns existed
+func backfillDetailSnapshots() error {
+	return DB.Exec(`
+		UPDATE transaction_details td
+		JOIN menus m ON m.id = td.menu_id
+		SET td.menu_name = m.name,
+			td.unit_price = ROUND(td.subtotal / td.qty, 2)
+		WHERE td.menu_name = '' AND td.qty > 0
+	`).Error
+}
+
+// backfillTaxBreakdown fills the subtotal and line totals of transactions
+// created before tax and service charge were tracked, when both equalled the total
+func backfillTaxBreakdown() error {
+	if err := DB.Exec(`
+		UPDATE transactions SET subtotal = total_amount
+		WHERE subtotal = 0 AND total_amount <> 0
+	`).Error; err != nil {
+		return err
+	}
+
+	return DB.Exec(`
+		UPDATE transaction_details SET line_total = subtotal
+		WHERE line_total = 0 AND subtotal <> 0
+	`).Error
+}
+
 // GetDB returns the database instance
 func GetDB() *gorm.DB {
 	return DB
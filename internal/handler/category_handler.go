@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"errors"
+	"service-cashier/internal/middleware"
+	"service-cashier/internal/model"
+	"service-cashier/internal/service"
+	"service-cashier/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CategoryHandler handles menu category HTTP requests
+type CategoryHandler struct {
+	categoryService *service.CategoryService
+}
+
+// NewCategoryHandler creates a new CategoryHandler instance
+func NewCategoryHandler(categoryService *service.CategoryService) *CategoryHandler {
+	return &CategoryHandler{categoryService: categoryService}
+}
+
+// GetCategories handles the list categories endpoint
+// GET /api/categories
+// Users who can manage menus also see inactive categories
+func (h *CategoryHandler) GetCategories(c *gin.Context) {
+	includeInactive := middleware.HasPermission(c, model.PermManageMenus)
+
+	categories, err := h.categoryService.GetAllCategories(includeInactive)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to retrieve categories")
+		return
+	}
+
+	utils.SuccessResponse(c, "Categories retrieved successfully", categories)
+}
+
+// GetCategory handles the get category by ID endpoint
+// GET /api/categories/:id
+func (h *CategoryHandler) GetCategory(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	category, err := h.categoryService.GetCategoryByID(id)
+	if err != nil {
+		h.handleError(c, err, "Failed to retrieve category")
+		return
+	}
+
+	utils.SuccessResponse(c, "Category retrieved successfully", category)
+}
+
+// CreateCategory handles the create category endpoint
+// POST /api/categories
+func (h *CategoryHandler) CreateCategory(c *gin.Context) {
+	var req service.CategoryRequest
+
+	// Bind JSON request body
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload")
+		return
+	}
+
+	category, err := h.categoryService.CreateCategory(&req)
+	if err != nil {
+		h.handleError(c, err, "Failed to create category")
+		return
+	}
+
+	utils.CreatedResponse(c, "Category created successfully", category)
+}
+
+// UpdateCategory handles the update category endpoint
+// PUT /api/categories/:id
+func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req service.CategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload")
+		return
+	}
+
+	category, err := h.categoryService.UpdateCategory(id, &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to update category")
+		return
+	}
+
+	utils.SuccessResponse(c, "Category updated successfully", category)
+}
+
+// DeleteCategory handles the delete category endpoint
+// DELETE /api/categories/:id
+func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.categoryService.DeleteCategory(id); err != nil {
+		h.handleError(c, err, "Failed to delete category")
+		return
+	}
+
+	utils.SuccessResponse(c, "Category deleted successfully", nil)
+}
+
+// handleError maps category service errors to HTTP responses
+func (h *CategoryHandler) handleError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrCategoryNotFound):
+		utils.NotFoundResponse(c, err.Error())
+	case errors.Is(err, service.ErrCategoryNameExists), errors.Is(err, service.ErrCategoryInUse):
+		utils.ConflictResponse(c, err.Error())
+	case errors.Is(err, service.ErrCategoryNameRequired):
+		utils.BadRequestResponse(c, err.Error())
+	default:
+		utils.InternalServerErrorResponse(c, fallback)
+	}
+}
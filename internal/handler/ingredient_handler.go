@@ -0,0 +1,312 @@
+package handler
+
+import (
+	"errors"
+	"service-cashier/internal/middleware"
+	"service-cashier/internal/service"
+	"service-cashier/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IngredientHandler handles ingredient and recipe HTTP requests
+type IngredientHandler struct {
+	ingredientService *service.IngredientService
+}
+
+// NewIngredientHandler creates a new IngredientHandler instance
+func NewIngredientHandler(ingredientService *service.IngredientService) *IngredientHandler {
+	return &IngredientHandler{ingredientService: ingredientService}
+}
+
+// GetIngredients handles the get all ingredients endpoint
+// GET /api/ingredients?low_stock=true
+func (h *IngredientHandler) GetIngredients(c *gin.Context) {
+	var query service.IngredientListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.BadRequestResponse(c, "Invalid query parameters")
+		return
+	}
+
+	ingredients, err := h.ingredientService.GetAllIngredients(&query)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to retrieve ingredients")
+		return
+	}
+
+	utils.SuccessResponse(c, "Ingredients retrieved successfully", ingredients)
+}
+
+// GetIngredient handles the get ingredient by ID endpoint
+// GET /api/ingredients/:id
+func (h *IngredientHandler) GetIngredient(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	ingredient, err := h.ingredientService.GetIngredientByID(id)
+	if err != nil {
+		h.handleError(c, err, "Failed to retrieve ingredient")
+		return
+	}
+
+	utils.SuccessResponse(c, "Ingredient retrieved successfully", ingredient)
+}
+
+// CreateIngredient handles the create ingredient endpoint
+// POST /api/ingredients
+func (h *IngredientHandler) CreateIngredient(c *gin.Context) {
+	var req service.CreateIngredientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload")
+		return
+	}
+
+	actorID, ok := middleware.GetUserID(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Unable to retrieve user information")
+		return
+	}
+
+	ingredient, err := h.ingredientService.CreateIngredient(actorID, &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to create ingredient")
+		return
+	}
+
+	utils.CreatedResponse(c, "Ingredient created successfully", ingredient)
+}
+
+// UpdateIngredient handles the update ingredient endpoint
+// PUT /api/ingredients/:id
+func (h *IngredientHandler) UpdateIngredient(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req service.UpdateIngredientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload")
+		return
+	}
+
+	ingredient, err := h.ingredientService.UpdateIngredient(id, &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to update ingredient")
+		return
+	}
+
+	utils.SuccessResponse(c, "Ingredient updated successfully", ingredient)
+}
+
+// DeleteIngredient handles the delete ingredient endpoint
+// DELETE /api/ingredients/:id
+func (h *IngredientHandler) DeleteIngredient(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.ingredientService.DeleteIngredient(id); err != nil {
+		h.handleError(c, err, "Failed to delete ingredient")
+		return
+	}
+
+	utils.SuccessResponse(c, "Ingredient deleted successfully", nil)
+}
+
+// ReceiveIngredient handles the ingredient delivery receiving endpoint
+// POST /api/ingredients/:id/stock/receive
+func (h *IngredientHandler) ReceiveIngredient(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req service.ReceiveStockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload")
+		return
+	}
+
+	actorID, ok := middleware.GetUserID(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Unable to retrieve user information")
+		return
+	}
+
+	movement, err := h.ingredientService.ReceiveIngredient(actorID, id, &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to receive stock")
+		return
+	}
+
+	utils.CreatedResponse(c, "Stock received successfully", movement)
+}
+
+// AdjustIngredient handles the ingredient stock correction endpoint
+// POST /api/ingredients/:id/stock/adjust
+func (h *IngredientHandler) AdjustIngredient(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req service.AdjustStockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload")
+		return
+	}
+
+	actorID, ok := middleware.GetUserID(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Unable to retrieve user information")
+		return
+	}
+
+	movement, err := h.ingredientService.AdjustIngredient(actorID, id, &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to adjust stock")
+		return
+	}
+
+	utils.CreatedResponse(c, "Stock adjusted successfully", movement)
+}
+
+// WasteIngredient handles the ingredient waste and spoilage write-off endpoint
+// POST /api/ingredients/:id/stock/waste
+func (h *IngredientHandler) WasteIngredient(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req service.WasteStockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload")
+		return
+	}
+
+	actorID, ok := middleware.GetUserID(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Unable to retrieve user information")
+		return
+	}
+
+	movement, err := h.ingredientService.WasteIngredient(actorID, id, &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to write off stock")
+		return
+	}
+
+	utils.CreatedResponse(c, "Stock written off successfully", movement)
+}
+
+// GetIngredientMovements handles the ingredient stock history endpoint
+// GET /api/ingredients/:id/movements?from=2024-01-01&to=2024-01-31&type=sale&cursor=&limit=50
+func (h *IngredientHandler) GetIngredientMovements(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var query service.StockMovementQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.BadRequestResponse(c, "Invalid query parameters")
+		return
+	}
+
+	list, err := h.ingredientService.ListIngredientMovements(id, &query)
+	if err != nil {
+		h.handleError(c, err, "Failed to retrieve ingredient movements")
+		return
+	}
+
+	utils.SuccessResponseWithMeta(c, "Ingredient movements retrieved successfully", list.Movements, utils.NewCursorMeta(list.Limit, list.NextCursor))
+}
+
+// SetMenuRecipe handles the menu recipe endpoint
+// PUT /api/menus/:id/recipe
+func (h *IngredientHandler) SetMenuRecipe(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req service.RecipeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload")
+		return
+	}
+
+	menu, err := h.ingredientService.SetMenuRecipe(id, &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to update recipe")
+		return
+	}
+
+	utils.SuccessResponse(c, "Recipe updated successfully", menu)
+}
+
+// GetOptionRecipe handles the get modifier option recipe endpoint
+// GET /api/modifier-options/:id/recipe
+func (h *IngredientHandler) GetOptionRecipe(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	recipe, err := h.ingredientService.GetOptionRecipe(id)
+	if err != nil {
+		h.handleError(c, err, "Failed to retrieve recipe")
+		return
+	}
+
+	utils.SuccessResponse(c, "Recipe retrieved successfully", recipe)
+}
+
+// SetOptionRecipe handles the modifier option recipe endpoint
+// PUT /api/modifier-options/:id/recipe
+func (h *IngredientHandler) SetOptionRecipe(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req service.RecipeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload")
+		return
+	}
+
+	recipe, err := h.ingredientService.SetOptionRecipe(id, &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to update recipe")
+		return
+	}
+
+	utils.SuccessResponse(c, "Recipe updated successfully", recipe)
+}
+
+// handleError maps ingredient service errors to HTTP responses
+func (h *IngredientHandler) handleError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrIngredientNotFound),
+		errors.Is(err, service.ErrMenuNotFound),
+		errors.Is(err, service.ErrModifierOptionNotFound):
+		utils.NotFoundResponse(c, err.Error())
+	case errors.Is(err, service.ErrIngredientNameExists),
+		errors.Is(err, service.ErrIngredientInUse):
+		utils.ConflictResponse(c, err.Error())
+	case errors.Is(err, service.ErrIngredientNameRequired),
+		errors.Is(err, service.ErrInvalidRecipe),
+		errors.Is(err, service.ErrInvalidStockQuery),
+		errors.Is(err, service.ErrStockReasonRequired),
+		errors.Is(err, service.ErrNegativeStock):
+		utils.BadRequestResponse(c, err.Error())
+	default:
+		utils.InternalServerErrorResponse(c, fallback)
+	}
+}
@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"errors"
+	"service-cashier/internal/middleware"
+	"service-cashier/internal/service"
+	"service-cashier/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// InventoryHandler handles stock ledger HTTP requests
+type InventoryHandler struct {
+	inventoryService *service.InventoryService
+}
+
+// NewInventoryHandler creates a new InventoryHandler instance
+func NewInventoryHandler(inventoryService *service.InventoryService) *InventoryHandler {
+	return &InventoryHandler{inventoryService: inventoryService}
+}
+
+// GetStockMovements handles the stock movement history endpoint
+// GET /api/menus/:id/stock-movements?from=2024-01-01&to=2024-01-31&type=sale&cursor=&limit=50
+func (h *InventoryHandler) GetStockMovements(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var query service.StockMovementQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.BadRequestResponse(c, "Invalid query parameters")
+		return
+	}
+
+	list, err := h.inventoryService.ListStockMovements(id, &query)
+	if err != nil {
+		h.handleError(c, err, "Failed to retrieve stock movements")
+		return
+	}
+
+	utils.SuccessResponseWithMeta(c, "Stock movements retrieved successfully", list.Movements, utils.NewCursorMeta(list.Limit, list.NextCursor))
+}
+
+// ReceiveStock handles the delivery receiving endpoint
+// POST /api/menus/:id/stock/receive
+func (h *InventoryHandler) ReceiveStock(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req service.ReceiveStockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload")
+		return
+	}
+
+	actorID, ok := middleware.GetUserID(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Unable to retrieve user information")
+		return
+	}
+
+	movement, err := h.inventoryService.ReceiveStock(actorID, id, &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to receive stock")
+		return
+	}
+
+	utils.CreatedResponse(c, "Stock received successfully", movement)
+}
+
+// AdjustStock handles the manual stock correction endpoint
+// POST /api/menus/:id/stock/adjust
+func (h *InventoryHandler) AdjustStock(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req service.AdjustStockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload")
+		return
+	}
+
+	actorID, ok := middleware.GetUserID(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Unable to retrieve user information")
+		return
+	}
+
+	movement, err := h.inventoryService.AdjustStock(actorID, id, &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to adjust stock")
+		return
+	}
+
+	utils.CreatedResponse(c, "Stock adjusted successfully", movement)
+}
+
+// WasteStock handles the waste and spoilage write-off endpoint
+// POST /api/menus/:id/stock/waste
+func (h *InventoryHandler) WasteStock(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req service.WasteStockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload")
+		return
+	}
+
+	actorID, ok := middleware.GetUserID(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Unable to retrieve user information")
+		return
+	}
+
+	movement, err := h.inventoryService.WasteStock(actorID, id, &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to write off stock")
+		return
+	}
+
+	utils.CreatedResponse(c, "Stock written off successfully", movement)
+}
+
+// GetLowStock handles the low-stock report endpoint
+// GET /api/inventory/low-stock
+func (h *InventoryHandler) GetLowStock(c *gin.Context) {
+	items, err := h.inventoryService.GetLowStock()
+	if err != nil {
+		h.handleError(c, err, "Failed to retrieve low-stock items")
+		return
+	}
+
+	utils.SuccessResponse(c, "Low-stock items retrieved successfully", items)
+}
+
+// handleError maps inventory service errors to HTTP responses
+func (h *InventoryHandler) handleError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrMenuNotFound):
+		utils.NotFoundResponse(c, err.Error())
+	case errors.Is(err, service.ErrInvalidStockQuery),
+		errors.Is(err, service.ErrStockReasonRequired),
+		errors.Is(err, service.ErrNegativeStock),
+		errors.Is(err, service.ErrMenuHasRecipe):
+		utils.BadRequestResponse(c, err.Error())
+	default:
+		utils.InternalServerErrorResponse(c, fallback)
+	}
+}
@@ -1,6 +1,9 @@
 package handler
 
 import (
+	"errors"
+	"service-cashier/internal/middleware"
+	"service-cashier/internal/model"
 	"service-cashier/internal/service"
 	"service-cashier/pkg/utils"
 
@@ -18,15 +21,237 @@ func NewMenuHandler(menuService *service.MenuService) *MenuHandler {
 }
 
 // GetMenus handles the get all menus endpoint
-// GET /api/menus
+// GET /api/menus?search=latte&available=true&min_price=10000&max_price=30000&sort=price&order=asc&page=1&limit=20
+// Items are sorted by category display order unless another sort is requested;
+// grouped=true returns the page's items as one entry per category; it is paginated
+// like the flat listing, with the page details in meta
 func (h *MenuHandler) GetMenus(c *gin.Context) {
-	// Retrieve all menus
-	menus, err := h.menuService.GetAllMenus()
+	var query service.MenuListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.BadRequestResponse(c, "Invalid query parameters")
+		return
+	}
+
+	// Archived items are only visible to users who can restore them
+	if query.Archived && !middleware.HasPermission(c, model.PermManageMenus) {
+		utils.ForbiddenResponse(c, "Insufficient permissions to view archived menus")
+		return
+	}
+
+	// Retrieve the matching menus
+	list, err := h.menuService.GetAllMenus(&query)
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to retrieve menus")
+		h.handleError(c, err, "Failed to retrieve menus")
+		return
+	}
+
+	meta := utils.NewPaginationMeta(list.Page, list.Limit, list.Total)
+	if query.Grouped {
+		utils.SuccessResponseWithMeta(c, "Menus retrieved successfully", service.GroupMenusByCategory(list.Menus), meta)
 		return
 	}
 
 	// Return success response
-	utils.SuccessResponse(c, "Menus retrieved successfully", menus)
+	utils.SuccessResponseWithMeta(c, "Menus retrieved successfully", list.Menus, meta)
+}
+
+// GetMenu handles the get menu by ID endpoint
+// GET /api/menus/:id
+func (h *MenuHandler) GetMenu(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	menu, err := h.menuService.GetMenuByID(id)
+	if err != nil {
+		h.handleError(c, err, "Failed to retrieve menu")
+		return
+	}
+
+	utils.SuccessResponse(c, "Menu retrieved successfully", menu)
+}
+
+// CreateMenu handles the create menu endpoint
+// POST /api/menus
+func (h *MenuHandler) CreateMenu(c *gin.Context) {
+	var req service.MenuRequest
+
+	// Bind JSON request body
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload")
+		return
+	}
+
+	actorID, ok := middleware.GetUserID(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Unable to retrieve user information")
+		return
+	}
+
+	menu, err := h.menuService.CreateMenu(actorID, &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to create menu")
+		return
+	}
+
+	utils.CreatedResponse(c, "Menu created successfully", menu)
+}
+
+// UpdateMenu handles the full menu update endpoint
+// PUT /api/menus/:id
+func (h *MenuHandler) UpdateMenu(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req service.MenuRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload")
+		return
+	}
+
+	actorID, ok := middleware.GetUserID(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Unable to retrieve user information")
+		return
+	}
+
+	menu, err := h.menuService.UpdateMenu(actorID, id, &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to update menu")
+		return
+	}
+
+	utils.SuccessResponse(c, "Menu updated successfully", menu)
+}
+
+// PatchMenu handles the partial menu update endpoint
+// PATCH /api/menus/:id
+func (h *MenuHandler) PatchMenu(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req service.PatchMenuRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload")
+		return
+	}
+
+	actorID, ok := middleware.GetUserID(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Unable to retrieve user information")
+		return
+	}
+
+	menu, err := h.menuService.PatchMenu(actorID, id, &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to update menu")
+		return
+	}
+
+	utils.SuccessResponse(c, "Menu updated successfully", menu)
+}
+
+// SetModifierGroups handles the assign modifier groups endpoint
+// PUT /api/menus/:id/modifier-groups
+func (h *MenuHandler) SetModifierGroups(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req service.MenuModifierGroupsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload")
+		return
+	}
+
+	menu, err := h.menuService.SetModifierGroups(id, &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to update menu modifier groups")
+		return
+	}
+
+	utils.SuccessResponse(c, "Menu modifier groups updated successfully", menu)
+}
+
+// DisableMenu handles the endpoint taking a menu item off sale
+// POST /api/menus/:id/disable
+func (h *MenuHandler) DisableMenu(c *gin.Context) {
+	h.setDisabled(c, true, "Menu disabled successfully")
+}
+
+// EnableMenu handles the endpoint putting a disabled menu item back on sale
+// POST /api/menus/:id/enable
+func (h *MenuHandler) EnableMenu(c *gin.Context) {
+	h.setDisabled(c, false, "Menu enabled successfully")
+}
+
+// setDisabled disables or enables the menu item identified by the id path parameter
+func (h *MenuHandler) setDisabled(c *gin.Context, disabled bool, message string) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	menu, err := h.menuService.SetMenuDisabled(id, disabled)
+	if err != nil {
+		h.handleError(c, err, "Failed to update menu")
+		return
+	}
+
+	utils.SuccessResponse(c, message, menu)
+}
+
+// RestoreMenu handles the restore archived menu endpoint
+// POST /api/menus/:id/restore
+func (h *MenuHandler) RestoreMenu(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	menu, err := h.menuService.RestoreMenu(id)
+	if err != nil {
+		h.handleError(c, err, "Failed to restore menu")
+		return
+	}
+
+	utils.SuccessResponse(c, "Menu restored successfully", menu)
+}
+
+// DeleteMenu handles the delete (archive) menu endpoint
+// DELETE /api/menus/:id
+func (h *MenuHandler) DeleteMenu(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.menuService.DeleteMenu(id); err != nil {
+		h.handleError(c, err, "Failed to delete menu")
+		return
+	}
+
+	utils.SuccessResponse(c, "Menu archived successfully", nil)
+}
+
+// handleError maps menu service errors to HTTP responses
+func (h *MenuHandler) handleError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrMenuNotFound):
+		utils.NotFoundResponse(c, err.Error())
+	case errors.Is(err, service.ErrCategoryNotFound), errors.Is(err, service.ErrModifierGroupNotFound):
+		utils.BadRequestResponse(c, err.Error())
+	case errors.Is(err, service.ErrMenuNameExists):
+		utils.ConflictResponse(c, err.Error())
+	case errors.Is(err, service.ErrMenuNameRequired), errors.Is(err, service.ErrInvalidMenuQuery):
+		utils.BadRequestResponse(c, err.Error())
+	default:
+		utils.InternalServerErrorResponse(c, fallback)
+	}
 }
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"errors"
+	"service-cashier/internal/service"
+	"service-cashier/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ModifierHandler handles menu modifier group HTTP requests
+type ModifierHandler struct {
+	modifierService *service.ModifierService
+}
+
+// NewModifierHandler creates a new ModifierHandler instance
+func NewModifierHandler(modifierService *service.ModifierService) *ModifierHandler {
+	return &ModifierHandler{modifierService: modifierService}
+}
+
+// GetModifierGroups handles the list modifier groups endpoint
+// GET /api/modifier-groups
+func (h *ModifierHandler) GetModifierGroups(c *gin.Context) {
+	groups, err := h.modifierService.GetAllModifierGroups()
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to retrieve modifier groups")
+		return
+	}
+
+	utils.SuccessResponse(c, "Modifier groups retrieved successfully", groups)
+}
+
+// GetModifierGroup handles the get modifier group by ID endpoint
+// GET /api/modifier-groups/:id
+func (h *ModifierHandler) GetModifierGroup(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	group, err := h.modifierService.GetModifierGroupByID(id)
+	if err != nil {
+		h.handleError(c, err, "Failed to retrieve modifier group")
+		return
+	}
+
+	utils.SuccessResponse(c, "Modifier group retrieved successfully", group)
+}
+
+// CreateModifierGroup handles the create modifier group endpoint
+// POST /api/modifier-groups
+func (h *ModifierHandler) CreateModifierGroup(c *gin.Context) {
+	var req service.ModifierGroupRequest
+
+	// Bind JSON request body
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload")
+		return
+	}
+
+	group, err := h.modifierService.CreateModifierGroup(&req)
+	if err != nil {
+		h.handleError(c, err, "Failed to create modifier group")
+		return
+	}
+
+	utils.CreatedResponse(c, "Modifier group created successfully", group)
+}
+
+// UpdateModifierGroup handles the update modifier group endpoint
+// PUT /api/modifier-groups/:id
+func (h *ModifierHandler) UpdateModifierGroup(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req service.ModifierGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload")
+		return
+	}
+
+	group, err := h.modifierService.UpdateModifierGroup(id, &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to update modifier group")
+		return
+	}
+
+	utils.SuccessResponse(c, "Modifier group updated successfully", group)
+}
+
+// DeleteModifierGroup handles the delete modifier group endpoint
+// DELETE /api/modifier-groups/:id
+func (h *ModifierHandler) DeleteModifierGroup(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.modifierService.DeleteModifierGroup(id); err != nil {
+		h.handleError(c, err, "Failed to delete modifier group")
+		return
+	}
+
+	utils.SuccessResponse(c, "Modifier group deleted successfully", nil)
+}
+
+// handleError maps modifier group service errors to HTTP responses
+func (h *ModifierHandler) handleError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrModifierGroupNotFound):
+		utils.NotFoundResponse(c, err.Error())
+	case errors.Is(err, service.ErrInvalidModifierGroup):
+		utils.BadRequestResponse(c, err.Error())
+	default:
+		utils.InternalServerErrorResponse(c, fallback)
+	}
+}
@@ -0,0 +1,19 @@
+package handler
+
+import (
+	"service-cashier/pkg/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parseIDParam parses a positive numeric path parameter
+// It writes a 400 response and returns false when the value is invalid
+func parseIDParam(c *gin.Context, name string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 64)
+	if err != nil || id == 0 {
+		utils.BadRequestResponse(c, "Invalid "+name+" parameter")
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"errors"
+	"service-cashier/internal/service"
+	"service-cashier/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PromotionHandler handles promotion management HTTP requests
+type PromotionHandler struct {
+	promotionService *service.PromotionService
+}
+
+// NewPromotionHandler creates a new PromotionHandler instance
+func NewPromotionHandler(promotionService *service.PromotionService) *PromotionHandler {
+	return &PromotionHandler{promotionService: promotionService}
+}
+
+// GetPromotions handles the list promotions endpoint
+// GET /api/promotions
+func (h *PromotionHandler) GetPromotions(c *gin.Context) {
+	promotions, err := h.promotionService.GetAllPromotions()
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to retrieve promotions")
+		return
+	}
+
+	utils.SuccessResponse(c, "Promotions retrieved successfully", promotions)
+}
+
+// GetPromotion handles the get promotion by ID endpoint
+// GET /api/promotions/:id
+func (h *PromotionHandler) GetPromotion(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	promotion, err := h.promotionService.GetPromotionByID(id)
+	if err != nil {
+		h.handleError(c, err, "Failed to retrieve promotion")
+		return
+	}
+
+	utils.SuccessResponse(c, "Promotion retrieved successfully", promotion)
+}
+
+// CreatePromotion handles the create promotion endpoint
+// POST /api/promotions
+func (h *PromotionHandler) CreatePromotion(c *gin.Context) {
+	var req service.PromotionRequest
+
+	// Bind JSON request body
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload")
+		return
+	}
+
+	promotion, err := h.promotionService.CreatePromotion(&req)
+	if err != nil {
+		h.handleError(c, err, "Failed to create promotion")
+		return
+	}
+
+	utils.CreatedResponse(c, "Promotion created successfully", promotion)
+}
+
+// UpdatePromotion handles the update promotion endpoint
+// PUT /api/promotions/:id
+func (h *PromotionHandler) UpdatePromotion(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req service.PromotionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload")
+		return
+	}
+
+	promotion, err := h.promotionService.UpdatePromotion(id, &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to update promotion")
+		return
+	}
+
+	utils.SuccessResponse(c, "Promotion updated successfully", promotion)
+}
+
+// DeletePromotion handles the delete promotion endpoint
+// DELETE /api/promotions/:id
+func (h *PromotionHandler) DeletePromotion(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.promotionService.DeletePromotion(id); err != nil {
+		h.handleError(c, err, "Failed to delete promotion")
+		return
+	}
+
+	utils.SuccessResponse(c, "Promotion deleted successfully", nil)
+}
+
+// handleError maps promotion service errors to HTTP responses
+func (h *PromotionHandler) handleError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrPromotionNotFound):
+		utils.NotFoundResponse(c, err.Error())
+	case errors.Is(err, service.ErrPromotionCodeExists), errors.Is(err, service.ErrPromotionInUse):
+		utils.ConflictResponse(c, err.Error())
+	case errors.Is(err, service.ErrInvalidPromotion):
+		utils.BadRequestResponse(c, err.Error())
+	default:
+		utils.InternalServerErrorResponse(c, fallback)
+	}
+}
@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"errors"
+	"service-cashier/internal/middleware"
+	"service-cashier/internal/model"
+	"service-cashier/internal/service"
+	"service-cashier/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PurchaseOrderHandler handles purchase order HTTP requests
+type PurchaseOrderHandler struct {
+	purchaseOrderService *service.PurchaseOrderService
+}
+
+// NewPurchaseOrderHandler creates a new PurchaseOrderHandler instance
+func NewPurchaseOrderHandler(purchaseOrderService *service.PurchaseOrderService) *PurchaseOrderHandler {
+	return &PurchaseOrderHandler{purchaseOrderService: purchaseOrderService}
+}
+
+// GetPurchaseOrders handles the purchase order listing endpoint
+// GET /api/purchase-orders?status=sent&supplier_id=1
+func (h *PurchaseOrderHandler) GetPurchaseOrders(c *gin.Context) {
+	var query service.PurchaseOrderListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.BadRequestResponse(c, "Invalid query parameters")
+		return
+	}
+
+	orders, err := h.purchaseOrderService.GetPurchaseOrders(&query)
+	if err != nil {
+		h.handleError(c, err, "Failed to retrieve purchase orders")
+		return
+	}
+
+	utils.SuccessResponse(c, "Purchase orders retrieved successfully", orders)
+}
+
+// GetPurchaseOrder handles the get purchase order by ID endpoint
+// GET /api/purchase-orders/:id
+func (h *PurchaseOrderHandler) GetPurchaseOrder(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	order, err := h.purchaseOrderService.GetPurchaseOrderByID(id)
+	if err != nil {
+		h.handleError(c, err, "Failed to retrieve purchase order")
+		return
+	}
+
+	utils.SuccessResponse(c, "Purchase order retrieved successfully", order)
+}
+
+// CreatePurchaseOrder handles the create draft purchase order endpoint
+// POST /api/purchase-orders
+func (h *PurchaseOrderHandler) CreatePurchaseOrder(c *gin.Context) {
+	var req service.PurchaseOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload")
+		return
+	}
+
+	actorID, ok := middleware.GetUserID(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Unable to retrieve user information")
+		return
+	}
+
+	order, err := h.purchaseOrderService.CreatePurchaseOrder(actorID, &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to create purchase order")
+		return
+	}
+
+	utils.CreatedResponse(c, "Purchase order created successfully", order)
+}
+
+// UpdatePurchaseOrder handles the update draft purchase order endpoint
+// PUT /api/purchase-orders/:id
+func (h *PurchaseOrderHandler) UpdatePurchaseOrder(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req service.PurchaseOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload")
+		return
+	}
+
+	order, err := h.purchaseOrderService.UpdatePurchaseOrder(id, &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to update purchase order")
+		return
+	}
+
+	utils.SuccessResponse(c, "Purchase order updated successfully", order)
+}
+
+// DeletePurchaseOrder handles the delete draft purchase order endpoint
+// DELETE /api/purchase-orders/:id
+func (h *PurchaseOrderHandler) DeletePurchaseOrder(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.purchaseOrderService.DeletePurchaseOrder(id); err != nil {
+		h.handleError(c, err, "Failed to delete purchase order")
+		return
+	}
+
+	utils.SuccessResponse(c, "Purchase order deleted successfully", nil)
+}
+
+// SendPurchaseOrder handles the endpoint marking a draft as sent to the supplier
+// POST /api/purchase-orders/:id/send
+func (h *PurchaseOrderHandler) SendPurchaseOrder(c *gin.Context) {
+	h.transition(c, h.purchaseOrderService.SendPurchaseOrder, "Purchase order sent successfully", "Failed to send purchase order")
+}
+
+// ClosePurchaseOrder handles the close purchase order endpoint
+// POST /api/purchase-orders/:id/close
+func (h *PurchaseOrderHandler) ClosePurchaseOrder(c *gin.Context) {
+	h.transition(c, h.purchaseOrderService.ClosePurchaseOrder, "Purchase order closed successfully", "Failed to close purchase order")
+}
+
+// ReceivePurchaseOrder handles the delivery receiving endpoint
+// POST /api/purchase-orders/:id/receive
+func (h *PurchaseOrderHandler) ReceivePurchaseOrder(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req service.ReceivePurchaseOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload")
+		return
+	}
+
+	actorID, ok := middleware.GetUserID(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Unable to retrieve user information")
+		return
+	}
+
+	order, err := h.purchaseOrderService.ReceivePurchaseOrder(actorID, id, &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to receive purchase order")
+		return
+	}
+
+	utils.SuccessResponse(c, "Delivery received successfully", order)
+}
+
+// transition sends or closes the purchase order identified by the id path parameter
+func (h *PurchaseOrderHandler) transition(c *gin.Context, action func(id uint) (*model.PurchaseOrder, error), message, fallback string) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	order, err := action(id)
+	if err != nil {
+		h.handleError(c, err, fallback)
+		return
+	}
+
+	utils.SuccessResponse(c, message, order)
+}
+
+// handleError maps purchase order service errors to HTTP responses
+func (h *PurchaseOrderHandler) handleError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrPurchaseOrderNotFound), errors.Is(err, service.ErrSupplierNotFound):
+		utils.NotFoundResponse(c, err.Error())
+	case errors.Is(err, service.ErrPurchaseOrderStatus):
+		utils.ConflictResponse(c, err.Error())
+	case errors.Is(err, service.ErrInvalidPurchaseOrder), errors.Is(err, service.ErrSupplierInactive):
+		utils.BadRequestResponse(c, err.Error())
+	default:
+		utils.InternalServerErrorResponse(c, fallback)
+	}
+}
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"errors"
+	"service-cashier/internal/middleware"
+	"service-cashier/internal/model"
+	"service-cashier/internal/service"
+	"service-cashier/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RefundHandler handles void and refund HTTP requests
+type RefundHandler struct {
+	refundService *service.RefundService
+}
+
+// NewRefundHandler creates a new RefundHandler instance
+func NewRefundHandler(refundService *service.RefundService) *RefundHandler {
+	return &RefundHandler{refundService: refundService}
+}
+
+// VoidTransaction handles the void transaction endpoint
+// POST /api/transactions/:id/void
+func (h *RefundHandler) VoidTransaction(c *gin.Context) {
+	h.createRefund(c, h.refundService.VoidTransaction, "Transaction voided successfully")
+}
+
+// RefundTransaction handles the refund transaction endpoint
+// POST /api/transactions/:id/refunds
+func (h *RefundHandler) RefundTransaction(c *gin.Context) {
+	h.createRefund(c, h.refundService.RefundTransaction, "Transaction refunded successfully")
+}
+
+// GetRefunds handles the list refunds of a transaction endpoint
+// GET /api/transactions/:id/refunds
+func (h *RefundHandler) GetRefunds(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	refunds, err := h.refundService.GetRefundsByTransaction(id)
+	if err != nil {
+		h.handleError(c, err, "Failed to retrieve refunds")
+		return
+	}
+
+	utils.SuccessResponse(c, "Refunds retrieved successfully", refunds)
+}
+
+// createRefund binds the refund payload and runs the given void or refund operation
+func (h *RefundHandler) createRefund(c *gin.Context, apply func(uint, uint, *service.RefundRequest) (*model.Refund, error), message string) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req service.RefundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload")
+		return
+	}
+
+	// The authenticated supervisor is recorded as the approving user
+	approverID, ok := middleware.GetUserID(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Unable to retrieve user information")
+		return
+	}
+
+	refund, err := apply(id, approverID, &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to process refund")
+		return
+	}
+
+	utils.CreatedResponse(c, message, refund)
+}
+
+// handleError maps refund service errors to HTTP responses
+func (h *RefundHandler) handleError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrTransactionNotFound):
+		utils.NotFoundResponse(c, err.Error())
+	case errors.Is(err, service.ErrNothingToRefund):
+		utils.ConflictResponse(c, err.Error())
+	case errors.Is(err, service.ErrInvalidRefundItem), errors.Is(err, service.ErrRefundReasonRequired):
+		utils.BadRequestResponse(c, err.Error())
+	default:
+		utils.InternalServerErrorResponse(c, fallback)
+	}
+}
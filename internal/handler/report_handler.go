@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"errors"
+	"service-cashier/internal/service"
+	"service-cashier/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReportHandler handles sales report HTTP requests
+type ReportHandler struct {
+	reportService *service.ReportService
+}
+
+// NewReportHandler creates a new ReportHandler instance
+func NewReportHandler(reportService *service.ReportService) *ReportHandler {
+	return &ReportHandler{reportService: reportService}
+}
+
+// GetMarginReport handles the gross margin report endpoint
+// GET /api/reports/margins?from=2024-01-01&to=2024-01-31&group_by=item|category|day
+func (h *ReportHandler) GetMarginReport(c *gin.Context) {
+	var query service.MarginReportQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.BadRequestResponse(c, "Invalid query parameters")
+		return
+	}
+
+	report, err := h.reportService.GetMarginReport(&query)
+	if err != nil {
+		h.handleError(c, err, "Failed to build margin report")
+		return
+	}
+
+	utils.SuccessResponse(c, "Margin report retrieved successfully", report)
+}
+
+// GetSalesSummary handles the sales summary report endpoint
+// GET /api/reports/sales-summary?from=2024-01-01&to=2024-01-31&outlet=main&limit=5
+func (h *ReportHandler) GetSalesSummary(c *gin.Context) {
+	var query service.SalesSummaryQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.BadRequestResponse(c, "Invalid query parameters")
+		return
+	}
+
+	report, err := h.reportService.GetSalesSummary(&query)
+	if err != nil {
+		h.handleError(c, err, "Failed to build sales summary")
+		return
+	}
+
+	utils.SuccessResponse(c, "Sales summary retrieved successfully", report)
+}
+
+// handleError maps report service errors to HTTP responses
+func (h *ReportHandler) handleError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidReportQuery):
+		utils.BadRequestResponse(c, err.Error())
+	default:
+		utils.InternalServerErrorResponse(c, fallback)
+	}
+}
@@ -0,0 +1,212 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"service-cashier/internal/middleware"
+	"service-cashier/internal/model"
+	"service-cashier/internal/service"
+	"service-cashier/pkg/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StockTakeHandler handles stock take HTTP requests
+type StockTakeHandler struct {
+	stockTakeService *service.StockTakeService
+}
+
+// NewStockTakeHandler creates a new StockTakeHandler instance
+func NewStockTakeHandler(stockTakeService *service.StockTakeService) *StockTakeHandler {
+	return &StockTakeHandler{stockTakeService: stockTakeService}
+}
+
+// GetStockTakes handles the stock take listing endpoint
+// GET /api/stock-takes?status=open
+func (h *StockTakeHandler) GetStockTakes(c *gin.Context) {
+	var query service.StockTakeListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.BadRequestResponse(c, "Invalid query parameters")
+		return
+	}
+
+	stockTakes, err := h.stockTakeService.GetStockTakes(&query)
+	if err != nil {
+		h.handleError(c, err, "Failed to retrieve stock takes")
+		return
+	}
+
+	utils.SuccessResponse(c, "Stock takes retrieved successfully", stockTakes)
+}
+
+// GetStockTake handles the get stock take by ID endpoint
+// GET /api/stock-takes/:id
+func (h *StockTakeHandler) GetStockTake(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	stockTake, err := h.stockTakeService.GetStockTakeByID(id)
+	if err != nil {
+		h.handleError(c, err, "Failed to retrieve stock take")
+		return
+	}
+
+	utils.SuccessResponse(c, "Stock take retrieved successfully", stockTake)
+}
+
+// CreateStockTake handles the start stock take endpoint
+// POST /api/stock-takes
+func (h *StockTakeHandler) CreateStockTake(c *gin.Context) {
+	var req service.CreateStockTakeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload")
+		return
+	}
+
+	actorID, ok := middleware.GetUserID(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Unable to retrieve user information")
+		return
+	}
+
+	stockTake, err := h.stockTakeService.CreateStockTake(actorID, &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to create stock take")
+		return
+	}
+
+	utils.CreatedResponse(c, "Stock take started successfully", stockTake)
+}
+
+// SubmitCounts handles the counted quantities endpoint
+// POST /api/stock-takes/:id/counts
+func (h *StockTakeHandler) SubmitCounts(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req service.StockTakeCountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload")
+		return
+	}
+
+	actorID, ok := middleware.GetUserID(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Unable to retrieve user information")
+		return
+	}
+
+	stockTake, err := h.stockTakeService.SubmitCounts(actorID, id, &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to record counts")
+		return
+	}
+
+	utils.SuccessResponse(c, "Counts recorded successfully", stockTake)
+}
+
+// ApproveStockTake handles the stock take approval endpoint
+// POST /api/stock-takes/:id/approve
+func (h *StockTakeHandler) ApproveStockTake(c *gin.Context) {
+	h.close(c, h.stockTakeService.ApproveStockTake, "Stock take approved successfully", "Failed to approve stock take")
+}
+
+// CancelStockTake handles the stock take cancellation endpoint
+// POST /api/stock-takes/:id/cancel
+func (h *StockTakeHandler) CancelStockTake(c *gin.Context) {
+	h.close(c, h.stockTakeService.CancelStockTake, "Stock take cancelled successfully", "Failed to cancel stock take")
+}
+
+// GetVarianceReport handles the variance report download endpoint
+// GET /api/stock-takes/:id/report.csv
+func (h *StockTakeHandler) GetVarianceReport(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	stockTake, err := h.stockTakeService.GetStockTakeByID(id)
+	if err != nil {
+		h.handleError(c, err, "Failed to retrieve stock take")
+		return
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write([]string{"item_type", "item_id", "name", "unit", "system_stock", "movements_during_count", "expected_stock", "counted_qty", "variance"})
+	for _, line := range stockTake.Lines {
+		counted := ""
+		if line.CountedQty != nil {
+			counted = strconv.Itoa(*line.CountedQty)
+		}
+		itemType, itemID := "menu", line.MenuID
+		if line.IngredientID != nil {
+			itemType, itemID = "ingredient", line.IngredientID
+		}
+		w.Write([]string{
+			itemType,
+			strconv.FormatUint(uint64(*itemID), 10),
+			line.Name,
+			line.Unit,
+			strconv.Itoa(line.SystemStock),
+			strconv.Itoa(line.MovementsDuringCount),
+			strconv.Itoa(line.ExpectedStock),
+			counted,
+			strconv.Itoa(line.Variance),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to build variance report")
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="stock-take-%d.csv"`, stockTake.ID))
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
+
+// close approves or cancels the stock take identified by the id path parameter
+func (h *StockTakeHandler) close(c *gin.Context, action func(actorID, id uint) (*model.StockTake, error), message, fallback string) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	actorID, ok := middleware.GetUserID(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Unable to retrieve user information")
+		return
+	}
+
+	stockTake, err := action(actorID, id)
+	if err != nil {
+		h.handleError(c, err, fallback)
+		return
+	}
+
+	utils.SuccessResponse(c, message, stockTake)
+}
+
+// handleError maps stock take service errors to HTTP responses
+func (h *StockTakeHandler) handleError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrStockTakeNotFound):
+		utils.NotFoundResponse(c, err.Error())
+	case errors.Is(err, service.ErrStockTakeClosed):
+		utils.ConflictResponse(c, err.Error())
+	case errors.Is(err, service.ErrInvalidStockTake),
+		errors.Is(err, service.ErrStockTakeIncomplete),
+		errors.Is(err, service.ErrNegativeStock):
+		utils.BadRequestResponse(c, err.Error())
+	default:
+		utils.InternalServerErrorResponse(c, fallback)
+	}
+}
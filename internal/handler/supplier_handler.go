@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"errors"
+	"service-cashier/internal/service"
+	"service-cashier/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SupplierHandler handles supplier HTTP requests
+type SupplierHandler struct {
+	supplierService *service.SupplierService
+}
+
+// NewSupplierHandler creates a new SupplierHandler instance
+func NewSupplierHandler(supplierService *service.SupplierService) *SupplierHandler {
+	return &SupplierHandler{supplierService: supplierService}
+}
+
+// GetSuppliers handles the list suppliers endpoint
+// GET /api/suppliers?active=true
+func (h *SupplierHandler) GetSuppliers(c *gin.Context) {
+	var query service.SupplierListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.BadRequestResponse(c, "Invalid query parameters")
+		return
+	}
+
+	suppliers, err := h.supplierService.GetAllSuppliers(&query)
+	if err != nil {
+		h.handleError(c, err, "Failed to retrieve suppliers")
+		return
+	}
+
+	utils.SuccessResponse(c, "Suppliers retrieved successfully", suppliers)
+}
+
+// GetSupplier handles the get supplier by ID endpoint
+// GET /api/suppliers/:id
+func (h *SupplierHandler) GetSupplier(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	supplier, err := h.supplierService.GetSupplierByID(id)
+	if err != nil {
+		h.handleError(c, err, "Failed to retrieve supplier")
+		return
+	}
+
+	utils.SuccessResponse(c, "Supplier retrieved successfully", supplier)
+}
+
+// CreateSupplier handles the create supplier endpoint
+// POST /api/suppliers
+func (h *SupplierHandler) CreateSupplier(c *gin.Context) {
+	var req service.SupplierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload")
+		return
+	}
+
+	supplier, err := h.supplierService.CreateSupplier(&req)
+	if err != nil {
+		h.handleError(c, err, "Failed to create supplier")
+		return
+	}
+
+	utils.CreatedResponse(c, "Supplier created successfully", supplier)
+}
+
+// UpdateSupplier handles the update supplier endpoint
+// PUT /api/suppliers/:id
+func (h *SupplierHandler) UpdateSupplier(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req service.SupplierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload")
+		return
+	}
+
+	supplier, err := h.supplierService.UpdateSupplier(id, &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to update supplier")
+		return
+	}
+
+	utils.SuccessResponse(c, "Supplier updated successfully", supplier)
+}
+
+// DeleteSupplier handles the delete supplier endpoint
+// DELETE /api/suppliers/:id
+func (h *SupplierHandler) DeleteSupplier(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.supplierService.DeleteSupplier(id); err != nil {
+		h.handleError(c, err, "Failed to delete supplier")
+		return
+	}
+
+	utils.SuccessResponse(c, "Supplier deleted successfully", nil)
+}
+
+// handleError maps supplier service errors to HTTP responses
+func (h *SupplierHandler) handleError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrSupplierNotFound):
+		utils.NotFoundResponse(c, err.Error())
+	case errors.Is(err, service.ErrSupplierNameExists), errors.Is(err, service.ErrSupplierInUse):
+		utils.ConflictResponse(c, err.Error())
+	case errors.Is(err, service.ErrSupplierNameRequired):
+		utils.BadRequestResponse(c, err.Error())
+	default:
+		utils.InternalServerErrorResponse(c, fallback)
+	}
+}
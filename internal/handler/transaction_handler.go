@@ -1,13 +1,27 @@
 package handler
 
 import (
+	"errors"
+	"net/http"
 	"service-cashier/internal/middleware"
+	"service-cashier/internal/model"
+	"service-cashier/internal/repository"
 	"service-cashier/internal/service"
 	"service-cashier/pkg/utils"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
+// maxIdempotencyKeyLength matches the size of the transactions.idempotency_key column
+const maxIdempotencyKeyLength = 100
+
+// transactionTotalsMeta is the response metadata of the cross-cashier transaction listing
+type transactionTotalsMeta struct {
+	utils.CursorMeta
+	Totals *repository.TransactionTotals `json:"totals"`
+}
+
 // TransactionHandler handles transaction-related HTTP requests
 type TransactionHandler struct {
 	transactionService *service.TransactionService
@@ -20,9 +34,16 @@ func NewTransactionHandler(transactionService *service.TransactionService) *Tran
 
 // Checkout handles the checkout endpoint
 // POST /api/checkout
+// An optional Idempotency-Key header makes retries of the same request safe
 func (h *TransactionHandler) Checkout(c *gin.Context) {
 	var req service.CheckoutRequest
 
+	idempotencyKey := strings.TrimSpace(c.GetHeader("Idempotency-Key"))
+	if len(idempotencyKey) > maxIdempotencyKeyLength {
+		utils.BadRequestResponse(c, "Idempotency-Key header must be at most 100 characters")
+		return
+	}
+
 	// Bind JSON request body
 	if err := c.ShouldBindJSON(&req); err != nil {
 		utils.BadRequestResponse(c, "Invalid request payload")
@@ -36,10 +57,27 @@ func (h *TransactionHandler) Checkout(c *gin.Context) {
 		return
 	}
 
+	// The role decides how much manual discount the cashier may give
+	role, _ := middleware.GetRole(c)
+
 	// Process checkout with concurrent item processing
-	response, err := h.transactionService.Checkout(cashierID, &req)
+	response, err := h.transactionService.Checkout(cashierID, role, idempotencyKey, &req)
 	if err != nil {
-		utils.BadRequestResponse(c, err.Error())
+		switch {
+		case errors.Is(err, service.ErrIdempotencyKeyConflict):
+			utils.ErrorResponse(c, http.StatusUnprocessableEntity, err.Error())
+		case errors.Is(err, service.ErrDiscountExceedsLimit):
+			utils.ForbiddenResponse(c, err.Error())
+		default:
+			utils.BadRequestResponse(c, err.Error())
+		}
+		return
+	}
+
+	// Return the original result for retried requests
+	if response.Replayed {
+		c.Header("Idempotent-Replayed", "true")
+		utils.SuccessResponse(c, "Checkout already processed", response)
 		return
 	}
 
@@ -48,7 +86,8 @@ func (h *TransactionHandler) Checkout(c *gin.Context) {
 }
 
 // GetTransactions handles the get transaction history endpoint
-// GET /api/transactions
+// GET /api/transactions?from=2024-01-01&to=2024-01-31&min_amount=&max_amount=&payment_method=cash&status=completed&cursor=&limit=20
+// Cashiers only see their own transactions, newest first
 func (h *TransactionHandler) GetTransactions(c *gin.Context) {
 	// Get cashier ID from JWT middleware context
 	cashierID, ok := middleware.GetUserID(c)
@@ -57,13 +96,85 @@ func (h *TransactionHandler) GetTransactions(c *gin.Context) {
 		return
 	}
 
+	var query service.TransactionListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.BadRequestResponse(c, "Invalid query parameters")
+		return
+	}
+
 	// Retrieve transactions for the cashier
-	transactions, err := h.transactionService.GetTransactionsByCashier(cashierID)
+	list, err := h.transactionService.ListTransactions(cashierID, &query)
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidTransactionQuery) {
+			utils.BadRequestResponse(c, err.Error())
+			return
+		}
 		utils.InternalServerErrorResponse(c, "Failed to retrieve transactions")
 		return
 	}
 
 	// Return success response
-	utils.SuccessResponse(c, "Transactions retrieved successfully", transactions)
+	utils.SuccessResponseWithMeta(c, "Transactions retrieved successfully", list.Transactions, utils.NewCursorMeta(list.Limit, list.NextCursor))
+}
+
+// GetAllTransactions handles the cross-cashier transaction listing endpoint
+// GET /api/transactions/all?cashier_id=3&from=2024-01-01&to=2024-01-31&min_amount=&max_amount=&cursor=&limit=20
+// The response meta carries cursor pagination and totals over every matching transaction
+func (h *TransactionHandler) GetAllTransactions(c *gin.Context) {
+	var query service.AllTransactionsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.BadRequestResponse(c, "Invalid query parameters")
+		return
+	}
+
+	list, err := h.transactionService.GetAllTransactions(&query)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidTransactionQuery) {
+			utils.BadRequestResponse(c, err.Error())
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to retrieve transactions")
+		return
+	}
+
+	meta := transactionTotalsMeta{
+		CursorMeta: utils.NewCursorMeta(list.Limit, list.NextCursor),
+		Totals:     list.Totals,
+	}
+	utils.SuccessResponseWithMeta(c, "Transactions retrieved successfully", list.Transactions, meta)
+}
+
+// GetTransaction handles the get transaction by ID endpoint
+// GET /api/transactions/:id
+// Cashiers can only read their own transactions, and get 404 for anyone else's;
+// supervisors and admins can read any
+func (h *TransactionHandler) GetTransaction(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Unable to retrieve user information")
+		return
+	}
+
+	transaction, err := h.transactionService.GetTransactionByID(id)
+	if err != nil {
+		if errors.Is(err, service.ErrTransactionNotFound) {
+			utils.NotFoundResponse(c, err.Error())
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to retrieve transaction")
+		return
+	}
+
+	// Another cashier's transaction is reported as missing so its ID is not confirmed
+	if transaction.CashierID != userID && !middleware.HasPermission(c, model.PermViewAllTransactions) {
+		utils.NotFoundResponse(c, service.ErrTransactionNotFound.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, "Transaction retrieved successfully", transaction)
 }
@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"errors"
+	"service-cashier/internal/middleware"
+	"service-cashier/internal/service"
+	"service-cashier/pkg/utils"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UserHandler handles user management HTTP requests
+type UserHandler struct {
+	userService *service.UserService
+}
+
+// NewUserHandler creates a new UserHandler instance
+func NewUserHandler(userService *service.UserService) *UserHandler {
+	return &UserHandler{userService: userService}
+}
+
+// GetUsers handles the list users endpoint
+// GET /api/users?archived=true
+func (h *UserHandler) GetUsers(c *gin.Context) {
+	archived, err := strconv.ParseBool(c.DefaultQuery("archived", "false"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid archived parameter")
+		return
+	}
+
+	users, err := h.userService.GetAllUsers(archived)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to retrieve users")
+		return
+	}
+
+	utils.SuccessResponse(c, "Users retrieved successfully", users)
+}
+
+// GetUser handles the get user by ID endpoint
+// GET /api/users/:id
+func (h *UserHandler) GetUser(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	user, err := h.userService.GetUserByID(id)
+	if err != nil {
+		h.handleError(c, err, "Failed to retrieve user")
+		return
+	}
+
+	utils.SuccessResponse(c, "User retrieved successfully", user)
+}
+
+// CreateUser handles the create user endpoint
+// POST /api/users
+func (h *UserHandler) CreateUser(c *gin.Context) {
+	var req service.CreateUserRequest
+
+	// Bind JSON request body
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload")
+		return
+	}
+
+	user, err := h.userService.CreateUser(&req)
+	if err != nil {
+		h.handleError(c, err, "Failed to create user")
+		return
+	}
+
+	utils.CreatedResponse(c, "User created successfully", user)
+}
+
+// DisableUser handles the disable user endpoint
+// POST /api/users/:id/disable
+func (h *UserHandler) DisableUser(c *gin.Context) {
+	h.setActive(c, false, "User disabled successfully")
+}
+
+// EnableUser handles the enable user endpoint
+// POST /api/users/:id/enable
+func (h *UserHandler) EnableUser(c *gin.Context) {
+	h.setActive(c, true, "User enabled successfully")
+}
+
+// UpdateRole handles the change user role endpoint
+// PUT /api/users/:id/role
+func (h *UserHandler) UpdateRole(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req service.UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload")
+		return
+	}
+
+	actorID, ok := middleware.GetUserID(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Unable to retrieve user information")
+		return
+	}
+
+	user, err := h.userService.UpdateUserRole(actorID, id, req.Role)
+	if err != nil {
+		h.handleError(c, err, "Failed to update user role")
+		return
+	}
+
+	utils.SuccessResponse(c, "User role updated successfully", user)
+}
+
+// ResetPassword handles the reset user password endpoint
+// POST /api/users/:id/reset-password
+func (h *UserHandler) ResetPassword(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req service.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request payload")
+		return
+	}
+
+	if err := h.userService.ResetPassword(id, req.Password); err != nil {
+		h.handleError(c, err, "Failed to reset password")
+		return
+	}
+
+	utils.SuccessResponse(c, "Password reset successfully", nil)
+}
+
+// DeleteUser handles the delete (archive) user endpoint
+// DELETE /api/users/:id
+func (h *UserHandler) DeleteUser(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	actorID, ok := middleware.GetUserID(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Unable to retrieve user information")
+		return
+	}
+
+	if err := h.userService.DeleteUser(actorID, id); err != nil {
+		h.handleError(c, err, "Failed to delete user")
+		return
+	}
+
+	utils.SuccessResponse(c, "User archived successfully", nil)
+}
+
+// RestoreUser handles the restore archived user endpoint
+// POST /api/users/:id/restore
+func (h *UserHandler) RestoreUser(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	user, err := h.userService.RestoreUser(id)
+	if err != nil {
+		h.handleError(c, err, "Failed to restore user")
+		return
+	}
+
+	utils.SuccessResponse(c, "User restored successfully", user)
+}
+
+// setActive enables or disables the user identified by the id path parameter
+func (h *UserHandler) setActive(c *gin.Context, active bool, message string) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	actorID, ok := middleware.GetUserID(c)
+	if !ok {
+		utils.UnauthorizedResponse(c, "Unable to retrieve user information")
+		return
+	}
+
+	user, err := h.userService.SetUserActive(actorID, id, active)
+	if err != nil {
+		h.handleError(c, err, "Failed to update user")
+		return
+	}
+
+	utils.SuccessResponse(c, message, user)
+}
+
+// handleError maps user service errors to HTTP responses
+func (h *UserHandler) handleError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrUserNotFound):
+		utils.NotFoundResponse(c, err.Error())
+	case errors.Is(err, service.ErrUsernameExists), errors.Is(err, service.ErrLastAdmin):
+		utils.ConflictResponse(c, err.Error())
+	case errors.Is(err, service.ErrInvalidRole), errors.Is(err, service.ErrCannotModifySelf):
+		utils.BadRequestResponse(c, err.Error())
+	default:
+		utils.InternalServerErrorResponse(c, fallback)
+	}
+}
@@ -1,14 +1,26 @@
 package middleware
 
 import (
+	"errors"
+	"service-cashier/internal/model"
 	"service-cashier/pkg/utils"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
+// UserFinder loads the user a token was issued to
+// Archived users must not be found
+type UserFinder interface {
+	FindByID(id uint) (*model.User, error)
+}
+
 // JWTAuth is a middleware that validates JWT tokens
-func JWTAuth(jwtSecret string) gin.HandlerFunc {
+// The user is loaded on every request so that disabling, archiving or demoting
+// an account takes effect immediately rather than when the token expires; the
+// role is taken from the user, not from the token
+func JWTAuth(jwtSecret string, users UserFinder) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get Authorization header
 		authHeader := c.GetHeader("Authorization")
@@ -37,9 +49,27 @@ func JWTAuth(jwtSecret string) gin.HandlerFunc {
 			return
 		}
 
+		// Reject tokens of users that have since been archived or disabled
+		user, err := users.FindByID(claims.UserID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				utils.UnauthorizedResponse(c, "User no longer exists")
+			} else {
+				utils.InternalServerErrorResponse(c, "Failed to verify user")
+			}
+			c.Abort()
+			return
+		}
+		if !user.Active {
+			utils.UnauthorizedResponse(c, "User account is disabled")
+			c.Abort()
+			return
+		}
+
 		// Attach user information to context
-		c.Set("user_id", claims.UserID)
-		c.Set("username", claims.Username)
+		c.Set("user_id", user.ID)
+		c.Set("username", user.Username)
+		c.Set("role", user.Role)
 
 		// Continue to next handler
 		c.Next()
@@ -67,3 +97,14 @@ func GetUsername(c *gin.Context) (string, bool) {
 	name, ok := username.(string)
 	return name, ok
 }
+
+// GetRole retrieves the user role from the Gin context
+func GetRole(c *gin.Context) (string, bool) {
+	role, exists := c.Get("role")
+	if !exists {
+		return "", false
+	}
+
+	r, ok := role.(string)
+	return r, ok
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"service-cashier/internal/model"
+	"service-cashier/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequirePermission is a middleware that only allows users whose role grants the permission
+// It must be registered after JWTAuth
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, ok := GetRole(c)
+		if !ok {
+			utils.UnauthorizedResponse(c, "Unable to retrieve user information")
+			c.Abort()
+			return
+		}
+
+		if !model.RoleHasPermission(role, permission) {
+			utils.ForbiddenResponse(c, "You do not have permission to perform this action")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// HasPermission checks whether the authenticated user's role grants a permission
+func HasPermission(c *gin.Context, permission string) bool {
+	role, ok := GetRole(c)
+	return ok && model.RoleHasPermission(role, permission)
+}
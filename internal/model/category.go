@@ -0,0 +1,21 @@
+package model
+
+import (
+	"time"
+)
+
+// Category groups menu items into tabs such as Coffee, Non-Coffee or Food
+// Categories are listed by DisplayOrder; inactive categories and their menu items
+// are hidden from the menu listing
+type Category struct {
+	ID           uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name         string    `gorm:"type:varchar(50);uniqueIndex;not null" json:"name"`
+	DisplayOrder int       `gorm:"not null;default:0" json:"display_order"`
+	Active       bool      `gorm:"not null;default:true" json:"active"`
+	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName specifies the table name for the Category model
+func (Category) TableName() string {
+	return "categories"
+}
@@ -0,0 +1,128 @@
+package model
+
+import (
+	"service-cashier/pkg/money"
+	"time"
+)
+
+// Ingredient units of measure
+// Quantities are whole numbers of the base unit, so 1.5 litres of milk is 1500 ml
+const (
+	IngredientUnitGram       = "g"
+	IngredientUnitMillilitre = "ml"
+	IngredientUnitPiece      = "pcs"
+)
+
+// Ingredient is a stocked raw material consumed by recipes, such as espresso
+// beans, milk or cups
+// Stock is kept in the base Unit. ReorderThreshold works like the menu threshold.
+// StockValue is the cost of the stock on hand at weighted average cost; keeping
+// the total rather than a per-unit cost avoids rounding a cost per gram or
+// millilitre to whole cents
+type Ingredient struct {
+	ID               uint        `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name             string      `gorm:"type:varchar(100);uniqueIndex;not null" json:"name"`
+	Unit             string      `gorm:"type:varchar(10);not null" json:"unit"`
+	Stock            int         `gorm:"not null;default:0" json:"stock"`
+	ReorderThreshold int         `gorm:"not null;default:0" json:"reorder_threshold"`
+	StockValue       money.Money `gorm:"type:decimal(10,2);not null;default:0" json:"stock_value"`
+	CreatedAt        time.Time   `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName specifies the table name for the Ingredient model
+func (Ingredient) TableName() string {
+	return "ingredients"
+}
+
+// IsLowStock reports whether the ingredient is at or below its reorder threshold
+func (i *Ingredient) IsLowStock() bool {
+	return i.Stock <= i.ReorderThreshold
+}
+
+// CostOf returns the weighted average cost of quantity units of the ingredient
+// It is zero while nothing is in stock, since the cost is then unknown
+func (i *Ingredient) CostOf(quantity int) money.Money {
+	if i.Stock <= 0 {
+		return 0
+	}
+	return i.StockValue.Prorate(quantity, i.Stock)
+}
+
+// StockValueAfter returns the StockValue after a change of quantity units
+// Received units add their totalCost when known and the current average cost
+// otherwise; units taken out remove their share at the average cost, and the
+// value is cleared when no stock is left
+func (i *Ingredient) StockValueAfter(quantity int, totalCost *money.Money) money.Money {
+	switch {
+	case quantity > 0 && totalCost != nil:
+		return i.StockValue.Add(*totalCost)
+	case quantity > 0:
+		return i.StockValue.Add(i.CostOf(quantity))
+	case i.Stock+quantity <= 0:
+		return 0
+	default:
+		return i.StockValue.Sub(i.CostOf(-quantity))
+	}
+}
+
+// MenuIngredient is one line of a menu item's recipe
+// Quantity is the amount of the ingredient used per unit sold, in its base unit
+type MenuIngredient struct {
+	ID           uint        `gorm:"primaryKey;autoIncrement" json:"id"`
+	MenuID       uint        `gorm:"not null;uniqueIndex:idx_menu_ingredients_menu_ingredient,priority:1" json:"menu_id"`
+	IngredientID uint        `gorm:"not null;uniqueIndex:idx_menu_ingredients_menu_ingredient,priority:2;index" json:"ingredient_id"`
+	Quantity     int         `gorm:"not null" json:"quantity"`
+	Ingredient   *Ingredient `gorm:"foreignKey:IngredientID" json:"ingredient,omitempty"`
+}
+
+// TableName specifies the table name for the MenuIngredient model
+func (MenuIngredient) TableName() string {
+	return "menu_ingredients"
+}
+
+// ModifierOptionIngredient is one line of a modifier option's recipe, such as
+// the extra espresso of an "Extra shot" option
+// Quantity is used per unit sold in addition to the menu item's own recipe
+type ModifierOptionIngredient struct {
+	ID               uint        `gorm:"primaryKey;autoIncrement" json:"id"`
+	ModifierOptionID uint        `gorm:"not null;uniqueIndex:idx_option_ingredients_option_ingredient,priority:1" json:"modifier_option_id"`
+	IngredientID     uint        `gorm:"not null;uniqueIndex:idx_option_ingredients_option_ingredient,priority:2;index" json:"ingredient_id"`
+	Quantity         int         `gorm:"not null" json:"quantity"`
+	Ingredient       *Ingredient `gorm:"foreignKey:IngredientID" json:"ingredient,omitempty"`
+}
+
+// TableName specifies the table name for the ModifierOptionIngredient model
+func (ModifierOptionIngredient) TableName() string {
+	return "modifier_option_ingredients"
+}
+
+// IngredientMovement is an append-only ledger entry for a change in ingredient stock
+// It mirrors StockMovement and uses the same movement types. Sales are recorded
+// per transaction detail so a void can return exactly what the line consumed.
+// TotalCost is the cost of a received delivery as a whole, since the cost of a
+// single gram or millilitre is usually below a cent
+type IngredientMovement struct {
+	ID                  uint         `gorm:"primaryKey;autoIncrement" json:"id"`
+	IngredientID        uint         `gorm:"not null;index:idx_ingredient_movements_ingredient_created,priority:1" json:"ingredient_id"`
+	Type                string       `gorm:"type:varchar(20);not null;index" json:"type"`
+	Quantity            int          `gorm:"not null" json:"quantity"`
+	StockBefore         int          `gorm:"not null" json:"stock_before"`
+	StockAfter          int          `gorm:"not null" json:"stock_after"`
+	UserID              *uint        `gorm:"index" json:"user_id"`
+	Reason              string       `gorm:"type:varchar(255);not null;default:''" json:"reason"`
+	TransactionID       *uint        `gorm:"index" json:"transaction_id,omitempty"`
+	TransactionDetailID *uint        `gorm:"index" json:"transaction_detail_id,omitempty"`
+	RefundID            *uint        `gorm:"index" json:"refund_id,omitempty"`
+	PurchaseOrderID     *uint        `gorm:"index" json:"purchase_order_id,omitempty"`
+	StockTakeID         *uint        `gorm:"index" json:"stock_take_id,omitempty"`
+	UnitCost            *money.Money `gorm:"type:decimal(10,2)" json:"unit_cost,omitempty"`
+	TotalCost           *money.Money `gorm:"type:decimal(10,2)" json:"total_cost,omitempty"`
+	Supplier            string       `gorm:"type:varchar(100);not null;default:''" json:"supplier,omitempty"`
+	ReferenceNumber     string       `gorm:"type:varchar(100);not null;default:'';index" json:"reference_number,omitempty"`
+	CreatedAt           time.Time    `gorm:"autoCreateTime;index:idx_ingredient_movements_ingredient_created,priority:2" json:"created_at"`
+}
+
+// TableName specifies the table name for the IngredientMovement model
+func (IngredientMovement) TableName() string {
+	return "ingredient_movements"
+}
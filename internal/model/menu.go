@@ -1,20 +1,159 @@
 package model
 
 import (
+	"service-cashier/pkg/money"
 	"time"
+
+	"gorm.io/gorm"
+)
+
+// Menu availability statuses
+const (
+	MenuAvailable = "available"
+	MenuLowStock  = "low"
+	MenuSoldOut   = "sold_out"
+	MenuDisabled  = "disabled"
 )
 
 // Menu represents a menu item available for purchase
+// Deleting a menu item archives it by setting DeletedAt; archived items are hidden
+// from listings and checkout but remain referenced by past transaction details.
+// ReorderThreshold is the stock level at or below which the item counts as low
+// stock; 0 only reports the item once it sells out. Disabled takes an item off
+// sale regardless of stock, and Availability is derived when the item is loaded.
+// Items with a Recipe are made to order: their own Stock is not used, and
+// AvailableStock is the number of portions the ingredients in stock allow.
+// CostPrice is the weighted average cost of one unit in stock, moved by received
+// deliveries; for items with a recipe RecipeCost is derived from the weighted
+// average cost of the ingredients instead
 type Menu struct {
-	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
-	Name      string    `gorm:"type:varchar(100);not null" json:"name"`
-	Price     float64   `gorm:"type:decimal(10,2);not null" json:"price"`
-	Stock     int       `gorm:"type:int;default:0" json:"stock"`
-	Image     string    `gorm:"type:varchar(255)" json:"image"`
-	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	ID               uint             `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name             string           `gorm:"type:varchar(100);not null" json:"name"`
+	CategoryID       *uint            `gorm:"index" json:"category_id"`
+	Price            money.Money      `gorm:"type:decimal(10,2);not null" json:"price"`
+	CostPrice        money.Money      `gorm:"type:decimal(10,2);not null;default:0" json:"cost_price"`
+	RecipeCost       *money.Money     `gorm:"-" json:"recipe_cost,omitempty"`
+	Stock            int              `gorm:"type:int;default:0" json:"stock"`
+	ReorderThreshold int              `gorm:"not null;default:0" json:"reorder_threshold"`
+	Disabled         bool             `gorm:"not null;default:false" json:"disabled"`
+	Availability     string           `gorm:"-" json:"availability"`
+	AvailableStock   int              `gorm:"-" json:"available_stock"`
+	TaxExempt        bool             `gorm:"not null;default:false" json:"tax_exempt"`
+	Image            string           `gorm:"type:varchar(255)" json:"image"`
+	CreatedAt        time.Time        `gorm:"autoCreateTime" json:"created_at"`
+	DeletedAt        gorm.DeletedAt   `gorm:"index" json:"deleted_at,omitempty"`
+	Category         *Category        `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
+	ModifierGroups   []ModifierGroup  `gorm:"many2many:menu_modifier_groups" json:"modifier_groups,omitempty"`
+	Recipe           []MenuIngredient `gorm:"foreignKey:MenuID" json:"recipe,omitempty"`
 }
 
 // TableName specifies the table name for the Menu model
 func (Menu) TableName() string {
 	return "menus"
 }
+
+// HasRecipe reports whether the menu item is made from ingredients
+// The recipe must have been loaded with its ingredients
+func (m *Menu) HasRecipe() bool {
+	return len(m.Recipe) > 0
+}
+
+// PortionsInStock returns how many units of the menu item can be sold: its own
+// stock, or for items with a recipe the portions the scarcest ingredient allows
+func (m *Menu) PortionsInStock() int {
+	if !m.HasRecipe() {
+		return m.Stock
+	}
+
+	portions := -1
+	for _, line := range m.Recipe {
+		if line.Ingredient == nil || line.Quantity <= 0 {
+			continue
+		}
+		available := line.Ingredient.Stock / line.Quantity
+		if portions < 0 || available < portions {
+			portions = available
+		}
+	}
+	if portions < 0 {
+		return 0
+	}
+	return portions
+}
+
+// AvailabilityStatus derives the availability of the menu item from its
+// disabled flag, the portions in stock and its reorder threshold
+func (m *Menu) AvailabilityStatus() string {
+	portions := m.PortionsInStock()
+	switch {
+	case m.Disabled:
+		return MenuDisabled
+	case portions <= 0:
+		return MenuSoldOut
+	case m.ReorderThreshold > 0 && portions <= m.ReorderThreshold:
+		return MenuLowStock
+	default:
+		return MenuAvailable
+	}
+}
+
+// RefreshAvailability recomputes the derived availability fields
+func (m *Menu) RefreshAvailability() {
+	m.AvailableStock = m.PortionsInStock()
+	m.Availability = m.AvailabilityStatus()
+}
+
+// UnitCost returns the cost of goods of one unit: the cost of its recipe at
+// the ingredients' weighted average cost, or its own CostPrice
+func (m *Menu) UnitCost() money.Money {
+	if !m.HasRecipe() {
+		return m.CostPrice
+	}
+
+	var cost money.Money
+	for _, line := range m.Recipe {
+		if line.Ingredient != nil {
+			cost = cost.Add(line.Ingredient.CostOf(line.Quantity))
+		}
+	}
+	return cost
+}
+
+// AverageCostAfter returns the weighted average CostPrice after quantity units
+// received at unitCost are added to the current stock
+// Stock below zero is treated as none, so a delivery is not averaged against
+// units that were never costed
+func (m *Menu) AverageCostAfter(quantity int, unitCost money.Money) money.Money {
+	onHand := m.Stock
+	if onHand < 0 {
+		onHand = 0
+	}
+	if onHand+quantity <= 0 {
+		return m.CostPrice
+	}
+	total := m.CostPrice.Mul(onHand).Add(unitCost.Mul(quantity))
+	return total.Prorate(1, onHand+quantity)
+}
+
+// refreshRecipeCost recomputes RecipeCost for items with a recipe
+func (m *Menu) refreshRecipeCost() {
+	m.RecipeCost = nil
+	if m.HasRecipe() {
+		cost := m.UnitCost()
+		m.RecipeCost = &cost
+	}
+}
+
+// AfterFind fills in the availability and recipe cost of loaded menu items
+func (m *Menu) AfterFind(tx *gorm.DB) error {
+	m.RefreshAvailability()
+	m.refreshRecipeCost()
+	return nil
+}
+
+// AfterSave keeps the availability and recipe cost current after the menu item is written
+func (m *Menu) AfterSave(tx *gorm.DB) error {
+	m.RefreshAvailability()
+	m.refreshRecipeCost()
+	return nil
+}
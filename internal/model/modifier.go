@@ -0,0 +1,66 @@
+package model
+
+import (
+	"service-cashier/pkg/money"
+	"time"
+)
+
+// Modifier group selection types
+const (
+	ModifierSelectionSingle = "single"
+	ModifierSelectionMulti  = "multi"
+)
+
+// ModifierGroup is a set of options a customer picks from when ordering a menu item,
+// such as Size, Sugar Level or Add-ons
+// MinSelections is the number of options that must be chosen; MaxSelections caps it,
+// with 0 meaning no limit. Single-select groups always allow at most one option
+type ModifierGroup struct {
+	ID            uint             `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name          string           `gorm:"type:varchar(50);not null" json:"name"`
+	SelectionType string           `gorm:"type:varchar(10);not null;default:single" json:"selection_type"`
+	MinSelections int              `gorm:"not null;default:0" json:"min_selections"`
+	MaxSelections int              `gorm:"not null;default:1" json:"max_selections"`
+	Active        bool             `gorm:"not null;default:true" json:"active"`
+	CreatedAt     time.Time        `gorm:"autoCreateTime" json:"created_at"`
+	Options       []ModifierOption `gorm:"foreignKey:ModifierGroupID" json:"options,omitempty"`
+}
+
+// TableName specifies the table name for the ModifierGroup model
+func (ModifierGroup) TableName() string {
+	return "modifier_groups"
+}
+
+// ModifierOption is a single choice within a modifier group
+// PriceDelta is added to the menu price and may be negative
+type ModifierOption struct {
+	ID              uint        `gorm:"primaryKey;autoIncrement" json:"id"`
+	ModifierGroupID uint        `gorm:"not null;index" json:"modifier_group_id"`
+	Name            string      `gorm:"type:varchar(50);not null" json:"name"`
+	PriceDelta      money.Money `gorm:"type:decimal(10,2);not null;default:0" json:"price_delta"`
+	DisplayOrder    int         `gorm:"not null;default:0" json:"display_order"`
+	Active          bool        `gorm:"not null;default:true" json:"active"`
+}
+
+// TableName specifies the table name for the ModifierOption model
+func (ModifierOption) TableName() string {
+	return "modifier_options"
+}
+
+// TransactionDetailModifier is a modifier option chosen for a sold item
+// Group and option names and the price delta are snapshots taken at sale time
+// so receipts and kitchen tickets are unaffected by later menu changes
+type TransactionDetailModifier struct {
+	ID                  uint        `gorm:"primaryKey;autoIncrement" json:"id"`
+	TransactionDetailID uint        `gorm:"not null;index" json:"transaction_detail_id"`
+	ModifierGroupID     uint        `gorm:"not null" json:"modifier_group_id"`
+	ModifierOptionID    uint        `gorm:"not null" json:"modifier_option_id"`
+	GroupName           string      `gorm:"type:varchar(50);not null" json:"group_name"`
+	OptionName          string      `gorm:"type:varchar(50);not null" json:"option_name"`
+	PriceDelta          money.Money `gorm:"type:decimal(10,2);not null;default:0" json:"price_delta"`
+}
+
+// TableName specifies the table name for the TransactionDetailModifier model
+func (TransactionDetailModifier) TableName() string {
+	return "transaction_detail_modifiers"
+}
@@ -0,0 +1,34 @@
+package model
+
+import (
+	"service-cashier/pkg/money"
+	"time"
+)
+
+// Payment methods accepted at checkout
+const (
+	PaymentMethodCash    = "cash"
+	PaymentMethodCard    = "card"
+	PaymentMethodQRIS    = "qris"
+	PaymentMethodEWallet = "ewallet"
+	PaymentMethodVoucher = "voucher"
+)
+
+// Payment represents one tender line used to settle a transaction
+// Amount is the part of the bill settled by this line; for cash, Tendered is
+// what the customer handed over and Change is what was given back
+type Payment struct {
+	ID            uint        `gorm:"primaryKey;autoIncrement" json:"id"`
+	TransactionID uint        `gorm:"not null;index" json:"transaction_id"`
+	Method        string      `gorm:"type:varchar(20);not null;index" json:"method"`
+	Amount        money.Money `gorm:"type:decimal(10,2);not null" json:"amount"`
+	Tendered      money.Money `gorm:"type:decimal(10,2);not null" json:"tendered"`
+	Change        money.Money `gorm:"type:decimal(10,2);not null;default:0" json:"change"`
+	Reference     string      `gorm:"type:varchar(100)" json:"reference,omitempty"`
+	CreatedAt     time.Time   `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName specifies the table name for the Payment model
+func (Payment) TableName() string {
+	return "transaction_payments"
+}
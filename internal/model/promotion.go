@@ -0,0 +1,91 @@
+package model
+
+import (
+	"service-cashier/pkg/money"
+	"time"
+)
+
+// Promotion types
+const (
+	PromotionTypePercentage = "percentage"
+	PromotionTypeFixed      = "fixed"
+	PromotionTypeBuyXGetY   = "buy_x_get_y"
+)
+
+// Promotion scopes
+const (
+	PromotionScopeOrder = "order"
+	PromotionScopeItem  = "item"
+)
+
+// Discount sources recorded against transactions
+const (
+	DiscountSourcePromotion = "promotion"
+	DiscountSourceManual    = "manual"
+)
+
+// Promotion is a promo code that can be redeemed at checkout
+// Percentage and fixed promotions apply to the whole order or, with the item
+// scope, to every unit of MenuID. Buy-X-get-Y promotions make GetQty units of
+// MenuID free for every BuyQty + GetQty units purchased
+type Promotion struct {
+	ID          uint        `gorm:"primaryKey;autoIncrement" json:"id"`
+	Code        string      `gorm:"type:varchar(50);uniqueIndex;not null" json:"code"`
+	Name        string      `gorm:"type:varchar(100);not null" json:"name"`
+	Type        string      `gorm:"type:varchar(20);not null" json:"type"`
+	Scope       string      `gorm:"type:varchar(10);not null;default:order" json:"scope"`
+	Percentage  money.Rate  `gorm:"type:decimal(5,2);not null;default:0" json:"percentage"`
+	Amount      money.Money `gorm:"type:decimal(10,2);not null;default:0" json:"amount"`
+	MaxDiscount money.Money `gorm:"type:decimal(10,2);not null;default:0" json:"max_discount"`
+	MinSubtotal money.Money `gorm:"type:decimal(10,2);not null;default:0" json:"min_subtotal"`
+	MenuID      *uint       `gorm:"index" json:"menu_id"`
+	BuyQty      int         `gorm:"not null;default:0" json:"buy_qty"`
+	GetQty      int         `gorm:"not null;default:0" json:"get_qty"`
+	StartsAt    *time.Time  `json:"starts_at"`
+	EndsAt      *time.Time  `json:"ends_at"`
+	UsageLimit  int         `gorm:"not null;default:0" json:"usage_limit"`
+	UsageCount  int         `gorm:"not null;default:0" json:"usage_count"`
+	Active      bool        `gorm:"not null;default:true" json:"active"`
+	CreatedAt   time.Time   `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time   `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name for the Promotion model
+func (Promotion) TableName() string {
+	return "promotions"
+}
+
+// IsRedeemableAt checks whether the promotion is active, within its validity
+// window and below its usage limit at the given time
+func (p *Promotion) IsRedeemableAt(now time.Time) bool {
+	if !p.Active {
+		return false
+	}
+	if p.StartsAt != nil && now.Before(*p.StartsAt) {
+		return false
+	}
+	if p.EndsAt != nil && now.After(*p.EndsAt) {
+		return false
+	}
+	return p.UsageLimit == 0 || p.UsageCount < p.UsageLimit
+}
+
+// TransactionDiscount records a discount applied to a transaction
+// TransactionDetailID is set for item-level discounts and nil for order-level ones;
+// order-level amounts are also allocated across TransactionDetail.DiscountAmount
+type TransactionDiscount struct {
+	ID                  uint        `gorm:"primaryKey;autoIncrement" json:"id"`
+	TransactionID       uint        `gorm:"not null;index" json:"transaction_id"`
+	TransactionDetailID *uint       `gorm:"index" json:"transaction_detail_id"`
+	PromotionID         *uint       `gorm:"index" json:"promotion_id"`
+	Source              string      `gorm:"type:varchar(20);not null" json:"source"`
+	Code                string      `gorm:"type:varchar(50)" json:"code,omitempty"`
+	Description         string      `gorm:"type:varchar(255);not null" json:"description"`
+	Amount              money.Money `gorm:"type:decimal(10,2);not null" json:"amount"`
+	CreatedAt           time.Time   `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName specifies the table name for the TransactionDiscount model
+func (TransactionDiscount) TableName() string {
+	return "transaction_discounts"
+}
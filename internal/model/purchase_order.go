@@ -0,0 +1,78 @@
+package model
+
+import (
+	"service-cashier/pkg/money"
+	"time"
+)
+
+// Purchase order statuses
+// A draft is edited freely; once sent its lines are fixed and deliveries are
+// received against it until every line has arrived or it is closed short
+const (
+	PurchaseOrderDraft             = "draft"
+	PurchaseOrderSent              = "sent"
+	PurchaseOrderPartiallyReceived = "partially_received"
+	PurchaseOrderReceived          = "received"
+	PurchaseOrderClosed            = "closed"
+)
+
+// PurchaseOrder is an order of stock from a supplier
+// ExpectedTotal is the expected cost of every line and ReceivedTotal the cost
+// of the goods received so far
+type PurchaseOrder struct {
+	ID            uint                `gorm:"primaryKey;autoIncrement" json:"id"`
+	SupplierID    uint                `gorm:"not null;index" json:"supplier_id"`
+	Supplier      *Supplier           `gorm:"foreignKey:SupplierID" json:"supplier,omitempty"`
+	Status        string              `gorm:"type:varchar(20);not null;default:draft;index" json:"status"`
+	Note          string              `gorm:"type:varchar(255);not null;default:''" json:"note"`
+	ExpectedTotal money.Money         `gorm:"type:decimal(10,2);not null;default:0" json:"expected_total"`
+	ReceivedTotal money.Money         `gorm:"type:decimal(10,2);not null;default:0" json:"received_total"`
+	CreatedByID   uint                `gorm:"not null;index" json:"created_by_id"`
+	CreatedAt     time.Time           `gorm:"autoCreateTime;index" json:"created_at"`
+	SentAt        *time.Time          `json:"sent_at"`
+	ReceivedAt    *time.Time          `json:"received_at"`
+	ClosedAt      *time.Time          `json:"closed_at"`
+	Lines         []PurchaseOrderLine `gorm:"foreignKey:PurchaseOrderID" json:"lines,omitempty"`
+}
+
+// TableName specifies the table name for the PurchaseOrder model
+func (PurchaseOrder) TableName() string {
+	return "purchase_orders"
+}
+
+// IsReceivable reports whether deliveries can still be received against the order
+func (po *PurchaseOrder) IsReceivable() bool {
+	return po.Status == PurchaseOrderSent || po.Status == PurchaseOrderPartiallyReceived
+}
+
+// PurchaseOrderLine is one ordered menu item or ingredient
+// Exactly one of MenuID and IngredientID is set. Name is a snapshot taken when
+// the line is written. Quantities are in stock units: pieces for menu items and
+// the base unit for ingredients. Costs are for the line as a whole, since the
+// cost of a gram or millilitre of an ingredient is usually below a cent:
+// ExpectedCost is for the ordered Quantity and ReceivedCost the actual cost of
+// the ReceivedQty units that arrived
+type PurchaseOrderLine struct {
+	ID              uint        `gorm:"primaryKey;autoIncrement" json:"id"`
+	PurchaseOrderID uint        `gorm:"not null;index" json:"purchase_order_id"`
+	MenuID          *uint       `gorm:"index" json:"menu_id,omitempty"`
+	IngredientID    *uint       `gorm:"index" json:"ingredient_id,omitempty"`
+	Name            string      `gorm:"type:varchar(100);not null" json:"name"`
+	Quantity        int         `gorm:"not null" json:"quantity"`
+	ExpectedCost    money.Money `gorm:"type:decimal(10,2);not null" json:"expected_cost"`
+	ReceivedQty     int         `gorm:"not null;default:0" json:"received_qty"`
+	ReceivedCost    money.Money `gorm:"type:decimal(10,2);not null;default:0" json:"received_cost"`
+}
+
+// TableName specifies the table name for the PurchaseOrderLine model
+func (PurchaseOrderLine) TableName() string {
+	return "purchase_order_lines"
+}
+
+// Outstanding returns the quantity still to be received
+func (l *PurchaseOrderLine) Outstanding() int {
+	if l.ReceivedQty >= l.Quantity {
+		return 0
+	}
+	return l.Quantity - l.ReceivedQty
+}
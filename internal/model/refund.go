@@ -0,0 +1,48 @@
+package model
+
+import (
+	"service-cashier/pkg/money"
+	"time"
+)
+
+// Refund document types
+const (
+	RefundTypeVoid   = "void"
+	RefundTypeRefund = "refund"
+)
+
+// Refund records a void or refund of some or all items of a transaction
+// Each refund returns the refunded quantities to menu stock
+type Refund struct {
+	ID            uint         `gorm:"primaryKey;autoIncrement" json:"id"`
+	TransactionID uint         `gorm:"not null;index" json:"transaction_id"`
+	Type          string       `gorm:"type:varchar(10);not null" json:"type"`
+	Reason        string       `gorm:"type:varchar(255);not null" json:"reason"`
+	ApprovedByID  uint         `gorm:"not null;index" json:"approved_by_id"`
+	TotalAmount   money.Money  `gorm:"type:decimal(10,2);not null" json:"total_amount"`
+	CreatedAt     time.Time    `gorm:"autoCreateTime" json:"created_at"`
+	Items         []RefundItem `gorm:"foreignKey:RefundID" json:"items,omitempty"`
+	ApprovedBy    *User        `gorm:"foreignKey:ApprovedByID" json:"approved_by,omitempty"`
+}
+
+// TableName specifies the table name for the Refund model
+func (Refund) TableName() string {
+	return "refunds"
+}
+
+// RefundItem represents the refunded quantity of a single transaction detail
+type RefundItem struct {
+	ID                  uint        `gorm:"primaryKey;autoIncrement" json:"id"`
+	RefundID            uint        `gorm:"not null;index" json:"refund_id"`
+	TransactionDetailID uint        `gorm:"not null;index" json:"transaction_detail_id"`
+	MenuID              uint        `gorm:"not null;index" json:"menu_id"`
+	MenuName            string      `gorm:"type:varchar(100);not null" json:"menu_name"`
+	Qty                 int         `gorm:"not null" json:"qty"`
+	Amount              money.Money `gorm:"type:decimal(10,2);not null" json:"amount"`
+	CreatedAt           time.Time   `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName specifies the table name for the RefundItem model
+func (RefundItem) TableName() string {
+	return "refund_items"
+}
@@ -0,0 +1,82 @@
+package model
+
+// Roles that can be assigned to a user
+const (
+	RoleCashier    = "cashier"
+	RoleSupervisor = "supervisor"
+	RoleAdmin      = "admin"
+)
+
+// Permissions checked by the authorization middleware
+const (
+	PermCheckout            = "transactions:checkout"
+	PermViewOwnTransactions = "transactions:read_own"
+	PermViewAllTransactions = "transactions:read_all"
+	PermVoidTransactions    = "transactions:void"
+	PermRefundTransactions  = "transactions:refund"
+	PermManageMenus         = "menus:manage"
+	PermToggleMenus         = "menus:toggle"
+	PermCountStock          = "stock:count"
+	PermManagePromotions    = "promotions:manage"
+	PermManagePurchasing    = "purchasing:manage"
+	PermManageUsers         = "users:manage"
+	PermViewReports         = "reports:read"
+)
+
+// rolePermissions maps each role to the permissions it grants
+var rolePermissions = map[string][]string{
+	RoleCashier: {
+		PermCheckout,
+		PermViewOwnTransactions,
+		PermToggleMenus,
+		PermCountStock,
+	},
+	RoleSupervisor: {
+		PermCheckout,
+		PermViewOwnTransactions,
+		PermViewAllTransactions,
+		PermVoidTransactions,
+		PermRefundTransactions,
+		PermManageMenus,
+		PermToggleMenus,
+		PermCountStock,
+		PermManagePromotions,
+		PermManagePurchasing,
+		PermViewReports,
+	},
+	RoleAdmin: {
+		PermCheckout,
+		PermViewOwnTransactions,
+		PermViewAllTransactions,
+		PermVoidTransactions,
+		PermRefundTransactions,
+		PermManageMenus,
+		PermToggleMenus,
+		PermCountStock,
+		PermManagePromotions,
+		PermManagePurchasing,
+		PermManageUsers,
+		PermViewReports,
+	},
+}
+
+// IsValidRole checks whether the given role is known
+func IsValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// RoleHasPermission checks whether the given role grants a permission
+func RoleHasPermission(role, permission string) bool {
+	for _, p := range rolePermissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// PermissionsForRole returns the permissions granted by a role
+func PermissionsForRole(role string) []string {
+	return append([]string(nil), rolePermissions[role]...)
+}
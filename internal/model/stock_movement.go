@@ -0,0 +1,46 @@
+package model
+
+import (
+	"service-cashier/pkg/money"
+	"time"
+)
+
+// Stock movement types
+const (
+	StockMovementSale       = "sale"
+	StockMovementRefund     = "refund"
+	StockMovementVoid       = "void"
+	StockMovementRestock    = "restock"
+	StockMovementAdjustment = "adjustment"
+	StockMovementWaste      = "waste"
+	StockMovementStockTake  = "stock_take"
+)
+
+// StockMovement is an append-only ledger entry for a change in menu stock
+// Quantity is signed: sales and waste are negative, refunds and restocks positive.
+// StockBefore and StockAfter record the stock level around the change, so the
+// ledger of an item explains every unit of its current stock. UnitCost, Supplier
+// and ReferenceNumber describe received deliveries
+type StockMovement struct {
+	ID              uint         `gorm:"primaryKey;autoIncrement" json:"id"`
+	MenuID          uint         `gorm:"not null;index:idx_stock_movements_menu_created,priority:1" json:"menu_id"`
+	Type            string       `gorm:"type:varchar(20);not null;index" json:"type"`
+	Quantity        int          `gorm:"not null" json:"quantity"`
+	StockBefore     int          `gorm:"not null" json:"stock_before"`
+	StockAfter      int          `gorm:"not null" json:"stock_after"`
+	UserID          *uint        `gorm:"index" json:"user_id"`
+	Reason          string       `gorm:"type:varchar(255);not null;default:''" json:"reason"`
+	TransactionID   *uint        `gorm:"index" json:"transaction_id,omitempty"`
+	RefundID        *uint        `gorm:"index" json:"refund_id,omitempty"`
+	StockTakeID     *uint        `gorm:"index" json:"stock_take_id,omitempty"`
+	PurchaseOrderID *uint        `gorm:"index" json:"purchase_order_id,omitempty"`
+	UnitCost        *money.Money `gorm:"type:decimal(10,2)" json:"unit_cost,omitempty"`
+	Supplier        string       `gorm:"type:varchar(100);not null;default:''" json:"supplier,omitempty"`
+	ReferenceNumber string       `gorm:"type:varchar(100);not null;default:'';index" json:"reference_number,omitempty"`
+	CreatedAt       time.Time    `gorm:"autoCreateTime;index:idx_stock_movements_menu_created,priority:2" json:"created_at"`
+}
+
+// TableName specifies the table name for the StockMovement model
+func (StockMovement) TableName() string {
+	return "stock_movements"
+}
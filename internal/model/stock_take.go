@@ -0,0 +1,65 @@
+package model
+
+import (
+	"time"
+)
+
+// Stock take statuses
+const (
+	StockTakeOpen      = "open"
+	StockTakeApproved  = "approved"
+	StockTakeCancelled = "cancelled"
+)
+
+// StockTake is a physical count of a set of menu items and ingredients
+// ClosedByID and ClosedAt record who approved or cancelled the session and when.
+// StartMovementID and StartIngredientMovementID mark the end of the menu and
+// ingredient stock ledgers when the session started, so stock movements made
+// while staff were counting can be told apart from variance
+type StockTake struct {
+	ID                        uint            `gorm:"primaryKey;autoIncrement" json:"id"`
+	Status                    string          `gorm:"type:varchar(10);not null;default:open;index" json:"status"`
+	Note                      string          `gorm:"type:varchar(255);not null;default:''" json:"note"`
+	StartMovementID           uint            `gorm:"not null" json:"-"`
+	StartIngredientMovementID uint            `gorm:"not null;default:0" json:"-"`
+	CreatedByID               uint            `gorm:"not null;index" json:"created_by_id"`
+	ClosedByID                *uint           `gorm:"index" json:"closed_by_id"`
+	CreatedAt                 time.Time       `gorm:"autoCreateTime" json:"created_at"`
+	ClosedAt                  *time.Time      `json:"closed_at"`
+	Lines                     []StockTakeLine `gorm:"foreignKey:StockTakeID" json:"lines,omitempty"`
+}
+
+// TableName specifies the table name for the StockTake model
+func (StockTake) TableName() string {
+	return "stock_takes"
+}
+
+// StockTakeLine is the count of a single menu item or ingredient within a stock take
+// Exactly one of MenuID and IngredientID is set; Name and Unit are snapshots,
+// and ingredient quantities are in the ingredient's base unit. SystemStock is
+// the stock when the session started. CountMovementID marks the end of the
+// line's stock ledger (menu or ingredient) when the count was submitted;
+// movements between the session start and the count make up
+// MovementsDuringCount. ExpectedStock and Variance (counted minus expected)
+// are final once the session is approved
+type StockTakeLine struct {
+	ID                   uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	StockTakeID          uint       `gorm:"not null;uniqueIndex:idx_stock_take_lines_take_menu,priority:1;uniqueIndex:idx_stock_take_lines_take_ingredient,priority:1" json:"stock_take_id"`
+	MenuID               *uint      `gorm:"uniqueIndex:idx_stock_take_lines_take_menu,priority:2" json:"menu_id,omitempty"`
+	IngredientID         *uint      `gorm:"uniqueIndex:idx_stock_take_lines_take_ingredient,priority:2" json:"ingredient_id,omitempty"`
+	Name                 string     `gorm:"type:varchar(100);not null" json:"name"`
+	Unit                 string     `gorm:"type:varchar(10);not null;default:''" json:"unit,omitempty"`
+	SystemStock          int        `gorm:"not null" json:"system_stock"`
+	CountedQty           *int       `json:"counted_qty"`
+	CountMovementID      uint       `gorm:"not null;default:0" json:"-"`
+	CountedByID          *uint      `json:"counted_by_id"`
+	CountedAt            *time.Time `json:"counted_at"`
+	MovementsDuringCount int        `gorm:"not null;default:0" json:"movements_during_count"`
+	ExpectedStock        int        `gorm:"not null;default:0" json:"expected_stock"`
+	Variance             int        `gorm:"not null;default:0" json:"variance"`
+}
+
+// TableName specifies the table name for the StockTakeLine model
+func (StockTakeLine) TableName() string {
+	return "stock_take_lines"
+}
@@ -0,0 +1,24 @@
+package model
+
+import (
+	"time"
+)
+
+// Supplier is a vendor that stock is ordered from
+// Inactive suppliers are kept for the history of their purchase orders but
+// cannot receive new ones
+type Supplier struct {
+	ID          uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name        string    `gorm:"type:varchar(100);uniqueIndex;not null" json:"name"`
+	ContactName string    `gorm:"type:varchar(100);not null;default:''" json:"contact_name"`
+	Phone       string    `gorm:"type:varchar(30);not null;default:''" json:"phone"`
+	Email       string    `gorm:"type:varchar(100);not null;default:''" json:"email"`
+	Address     string    `gorm:"type:varchar(255);not null;default:''" json:"address"`
+	Active      bool      `gorm:"not null;default:true" json:"active"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName specifies the table name for the Supplier model
+func (Supplier) TableName() string {
+	return "suppliers"
+}
@@ -1,17 +1,48 @@
 package model
 
 import (
+	"service-cashier/pkg/money"
 	"time"
 )
 
+// Transaction statuses
+const (
+	TransactionStatusCompleted         = "completed"
+	TransactionStatusPartiallyRefunded = "partially_refunded"
+	TransactionStatusRefunded          = "refunded"
+	TransactionStatusVoided            = "voided"
+)
+
 // Transaction represents a completed checkout transaction
+// Subtotal is the gross value of the items before discounts. TotalAmount is the
+// grand total: Subtotal minus DiscountAmount plus ServiceCharge plus any tax added
+// on top of the menu prices, with the tax rules in force snapshotted alongside
+// IdempotencyKey and RequestHash identify the checkout request that created it
+// so that client retries can be answered without charging twice
 type Transaction struct {
-	ID          uint                `gorm:"primaryKey;autoIncrement" json:"id"`
-	CashierID   uint                `gorm:"not null;index" json:"cashier_id"`
-	TotalAmount float64             `gorm:"type:decimal(10,2);not null" json:"total_amount"`
-	CreatedAt   time.Time           `gorm:"autoCreateTime" json:"created_at"`
-	Details     []TransactionDetail `gorm:"foreignKey:TransactionID" json:"details,omitempty"`
-	Cashier     User                `gorm:"foreignKey:CashierID" json:"cashier,omitempty"`
+	ID                uint                  `gorm:"primaryKey;autoIncrement" json:"id"`
+	CashierID         uint                  `gorm:"not null;index;uniqueIndex:idx_transactions_cashier_idempotency,priority:1" json:"cashier_id"`
+	OutletCode        string                `gorm:"type:varchar(50);not null;default:main;index" json:"outlet_code"`
+	Subtotal          money.Money           `gorm:"type:decimal(10,2);not null;default:0" json:"subtotal"`
+	DiscountAmount    money.Money           `gorm:"type:decimal(10,2);not null;default:0" json:"discount_amount"`
+	ServiceCharge     money.Money           `gorm:"type:decimal(10,2);not null;default:0" json:"service_charge"`
+	TaxAmount         money.Money           `gorm:"type:decimal(10,2);not null;default:0" json:"tax_amount"`
+	TotalAmount       money.Money           `gorm:"type:decimal(10,2);not null" json:"total_amount"`
+	TaxRate           money.Rate            `gorm:"type:decimal(5,2);not null;default:0" json:"tax_rate"`
+	TaxInclusive      bool                  `gorm:"not null;default:false" json:"tax_inclusive"`
+	ServiceChargeRate money.Rate            `gorm:"type:decimal(5,2);not null;default:0" json:"service_charge_rate"`
+	Status            string                `gorm:"type:varchar(20);not null;default:completed;index" json:"status"`
+	RefundedAmount    money.Money           `gorm:"type:decimal(10,2);not null;default:0" json:"refunded_amount"`
+	PaidAmount        money.Money           `gorm:"type:decimal(10,2);not null;default:0" json:"paid_amount"`
+	ChangeAmount      money.Money           `gorm:"type:decimal(10,2);not null;default:0" json:"change_amount"`
+	IdempotencyKey    *string               `gorm:"type:varchar(100);uniqueIndex:idx_transactions_cashier_idempotency,priority:2" json:"-"`
+	RequestHash       string                `gorm:"type:char(64);not null;default:''" json:"-"`
+	CreatedAt         time.Time             `gorm:"autoCreateTime" json:"created_at"`
+	Details           []TransactionDetail   `gorm:"foreignKey:TransactionID" json:"details,omitempty"`
+	Payments          []Payment             `gorm:"foreignKey:TransactionID" json:"payments,omitempty"`
+	Discounts         []TransactionDiscount `gorm:"foreignKey:TransactionID" json:"discounts,omitempty"`
+	Refunds           []Refund              `gorm:"foreignKey:TransactionID" json:"refunds,omitempty"`
+	Cashier           User                  `gorm:"foreignKey:CashierID" json:"cashier,omitempty"`
 }
 
 // TableName specifies the table name for the Transaction model
@@ -20,14 +51,36 @@ func (Transaction) TableName() string {
 }
 
 // TransactionDetail represents individual items in a transaction
+// MenuName and UnitPrice are snapshots taken at sale time so receipts keep
+// showing what was sold even after the menu item is renamed, repriced or removed
+// UnitPrice includes the price deltas of the chosen modifiers. UnitCost and
+// CostAmount snapshot the cost of goods of the line at sale time, including the
+// ingredients of recipes and modifiers, so margins stay as they were when sold
 type TransactionDetail struct {
-	ID            uint      `gorm:"primaryKey;autoIncrement" json:"id"`
-	TransactionID uint      `gorm:"not null;index" json:"transaction_id"`
-	MenuID        uint      `gorm:"not null;index" json:"menu_id"`
-	Qty           int       `gorm:"not null" json:"qty"`
-	Subtotal      float64   `gorm:"type:decimal(10,2);not null" json:"subtotal"`
-	CreatedAt     time.Time `gorm:"autoCreateTime" json:"created_at"`
-	Menu          Menu      `gorm:"foreignKey:MenuID" json:"menu,omitempty"`
+	ID             uint                        `gorm:"primaryKey;autoIncrement" json:"id"`
+	TransactionID  uint                        `gorm:"not null;index" json:"transaction_id"`
+	MenuID         uint                        `gorm:"not null;index" json:"menu_id"`
+	MenuName       string                      `gorm:"type:varchar(100);not null;default:''" json:"menu_name"`
+	UnitPrice      money.Money                 `gorm:"type:decimal(10,2);not null;default:0" json:"unit_price"`
+	Qty            int                         `gorm:"not null" json:"qty"`
+	Subtotal       money.Money                 `gorm:"type:decimal(10,2);not null" json:"subtotal"`
+	UnitCost       money.Money                 `gorm:"type:decimal(10,2);not null;default:0" json:"unit_cost"`
+	CostAmount     money.Money                 `gorm:"type:decimal(10,2);not null;default:0" json:"cost_amount"`
+	DiscountAmount money.Money                 `gorm:"type:decimal(10,2);not null;default:0" json:"discount_amount"`
+	TaxExempt      bool                        `gorm:"not null;default:false" json:"tax_exempt"`
+	ServiceCharge  money.Money                 `gorm:"type:decimal(10,2);not null;default:0" json:"service_charge"`
+	TaxAmount      money.Money                 `gorm:"type:decimal(10,2);not null;default:0" json:"tax_amount"`
+	LineTotal      money.Money                 `gorm:"type:decimal(10,2);not null;default:0" json:"line_total"`
+	RefundedQty    int                         `gorm:"not null;default:0" json:"refunded_qty"`
+	RefundedAmount money.Money                 `gorm:"type:decimal(10,2);not null;default:0" json:"refunded_amount"`
+	CreatedAt      time.Time                   `gorm:"autoCreateTime" json:"created_at"`
+	Menu           *Menu                       `gorm:"foreignKey:MenuID" json:"menu,omitempty"`
+	Modifiers      []TransactionDetailModifier `gorm:"foreignKey:TransactionDetailID" json:"modifiers,omitempty"`
+}
+
+// RemainingQty returns the quantity that has not been refunded yet
+func (d *TransactionDetail) RemainingQty() int {
+	return d.Qty - d.RefundedQty
 }
 
 // TableName specifies the table name for the TransactionDetail model
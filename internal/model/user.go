@@ -2,14 +2,21 @@ package model
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
 // User represents a cashier user in the system
+// Deleting a user archives it by setting DeletedAt so that the transactions they
+// rang up keep their cashier; archived users cannot log in
 type User struct {
-	ID           uint      `gorm:"primaryKey;autoIncrement" json:"id"`
-	Username     string    `gorm:"type:varchar(50);uniqueIndex;not null" json:"username"`
-	PasswordHash string    `gorm:"type:varchar(255);not null" json:"-"` // "-" prevents password from being serialized to JSON
-	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"`
+	ID           uint           `gorm:"primaryKey;autoIncrement" json:"id"`
+	Username     string         `gorm:"type:varchar(50);uniqueIndex;not null" json:"username"`
+	PasswordHash string         `gorm:"type:varchar(255);not null" json:"-"` // "-" prevents password from being serialized to JSON
+	Role         string         `gorm:"type:varchar(20);not null;default:cashier" json:"role"`
+	Active       bool           `gorm:"not null;default:true" json:"active"`
+	CreatedAt    time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
 }
 
 // TableName specifies the table name for the User model
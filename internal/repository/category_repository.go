@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"service-cashier/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// CategoryRepository handles menu category data access operations
+type CategoryRepository struct {
+	db *gorm.DB
+}
+
+// NewCategoryRepository creates a new CategoryRepository instance
+func NewCategoryRepository(db *gorm.DB) *CategoryRepository {
+	return &CategoryRepository{db: db}
+}
+
+// GetAll retrieves all categories ordered by display order
+func (r *CategoryRepository) GetAll(activeOnly bool) ([]model.Category, error) {
+	var categories []model.Category
+	query := r.db.Order("display_order ASC, name ASC")
+	if activeOnly {
+		query = query.Where("active = ?", true)
+	}
+	err := query.Find(&categories).Error
+	return categories, err
+}
+
+// FindByID retrieves a category by ID
+func (r *CategoryRepository) FindByID(id uint) (*model.Category, error) {
+	var category model.Category
+	err := r.db.First(&category, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// Create creates a new category
+func (r *CategoryRepository) Create(category *model.Category) error {
+	return r.db.Create(category).Error
+}
+
+// Update updates an existing category
+func (r *CategoryRepository) Update(category *model.Category) error {
+	return r.db.Save(category).Error
+}
+
+// Delete deletes a category by ID
+// Archived menu items in the category are left without a category
+func (r *CategoryRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Model(&model.Menu{}).
+			Where("category_id = ? AND deleted_at IS NOT NULL", id).
+			Update("category_id", nil).Error
+		if err != nil {
+			return err
+		}
+		return tx.Delete(&model.Category{}, id).Error
+	})
+}
+
+// HasMenus checks whether any live menu item belongs to the category
+func (r *CategoryRepository) HasMenus(id uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.Menu{}).Where("category_id = ?", id).Count(&count).Error
+	return count > 0, err
+}
@@ -0,0 +1,278 @@
+package repository
+
+import (
+	"service-cashier/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IngredientRepository handles ingredient, recipe and ingredient ledger data access operations
+type IngredientRepository struct {
+	db *gorm.DB
+}
+
+// NewIngredientRepository creates a new IngredientRepository instance
+func NewIngredientRepository(db *gorm.DB) *IngredientRepository {
+	return &IngredientRepository{db: db}
+}
+
+// GetAll retrieves all ingredients ordered by name
+// lowStockOnly limits the list to ingredients at or below their reorder threshold,
+// lowest stock first
+func (r *IngredientRepository) GetAll(lowStockOnly bool) ([]model.Ingredient, error) {
+	var ingredients []model.Ingredient
+	query := r.db.Order("name ASC")
+	if lowStockOnly {
+		query = r.db.Where("stock <= reorder_threshold").Order("stock ASC, name ASC")
+	}
+	err := query.Find(&ingredients).Error
+	return ingredients, err
+}
+
+// FindByID retrieves an ingredient by ID
+func (r *IngredientRepository) FindByID(id uint) (*model.Ingredient, error) {
+	var ingredient model.Ingredient
+	err := r.db.First(&ingredient, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &ingredient, nil
+}
+
+// FindByIDs retrieves the ingredients with the given IDs
+func (r *IngredientRepository) FindByIDs(ids []uint) ([]model.Ingredient, error) {
+	var ingredients []model.Ingredient
+	if len(ids) == 0 {
+		return ingredients, nil
+	}
+	err := r.db.Where("id IN ?", ids).Find(&ingredients).Error
+	return ingredients, err
+}
+
+// FindByIDsWithLock retrieves ingredients with row-level locking for updates
+// Rows are locked in ID order so concurrent checkouts sharing ingredients
+// cannot deadlock on each other
+func (r *IngredientRepository) FindByIDsWithLock(tx *gorm.DB, ids []uint) ([]model.Ingredient, error) {
+	var ingredients []model.Ingredient
+	if len(ids) == 0 {
+		return ingredients, nil
+	}
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id IN ?", ids).
+		Order("id ASC").
+		Find(&ingredients).Error
+	return ingredients, err
+}
+
+// FindAllWithLock retrieves every ingredient with row-level locking in ID order
+func (r *IngredientRepository) FindAllWithLock(tx *gorm.DB) ([]model.Ingredient, error) {
+	var ingredients []model.Ingredient
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Order("id ASC").Find(&ingredients).Error
+	return ingredients, err
+}
+
+// FindByIDWithLock retrieves an ingredient by ID with row-level locking for updates
+func (r *IngredientRepository) FindByIDWithLock(tx *gorm.DB, id uint) (*model.Ingredient, error) {
+	var ingredient model.Ingredient
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&ingredient, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &ingredient, nil
+}
+
+// ExistsByName checks whether another ingredient uses the name
+func (r *IngredientRepository) ExistsByName(name string, excludeID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.Ingredient{}).
+		Where("name = ? AND id <> ?", name, excludeID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Create creates a new ingredient within a database transaction
+func (r *IngredientRepository) Create(tx *gorm.DB, ingredient *model.Ingredient) error {
+	return tx.Create(ingredient).Error
+}
+
+// Update updates an existing ingredient
+// Stock and its value only change through ApplyMovement
+func (r *IngredientRepository) Update(ingredient *model.Ingredient) error {
+	return r.db.Omit("stock", "stock_value").Save(ingredient).Error
+}
+
+// IsUsed checks whether any recipe uses the ingredient
+func (r *IngredientRepository) IsUsed(id uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.MenuIngredient{}).Where("ingredient_id = ?", id).Count(&count).Error
+	if err != nil || count > 0 {
+		return count > 0, err
+	}
+	err = r.db.Model(&model.ModifierOptionIngredient{}).Where("ingredient_id = ?", id).Count(&count).Error
+	return count > 0, err
+}
+
+// Delete deletes an ingredient by ID
+// Its ledger is kept for the stock history
+func (r *IngredientRepository) Delete(id uint) error {
+	return r.db.Delete(&model.Ingredient{}, id).Error
+}
+
+// ApplyMovement changes the stock of an ingredient by movement.Quantity and
+// appends the movement to the ingredient ledger within a database transaction
+// The stock value moves at weighted average cost (see Ingredient.StockValueAfter).
+// The ingredient row must already be locked by the caller; ingredient.Stock and
+// ingredient.StockValue are updated to the new level.
+func (r *IngredientRepository) ApplyMovement(tx *gorm.DB, ingredient *model.Ingredient, movement *model.IngredientMovement) error {
+	movement.IngredientID = ingredient.ID
+	movement.StockBefore = ingredient.Stock
+	movement.StockAfter = ingredient.Stock + movement.Quantity
+	value := ingredient.StockValueAfter(movement.Quantity, movement.TotalCost)
+
+	err := tx.Model(&model.Ingredient{}).Where("id = ?", ingredient.ID).Updates(map[string]interface{}{
+		"stock":       movement.StockAfter,
+		"stock_value": value,
+	}).Error
+	if err != nil {
+		return err
+	}
+	if err := tx.Create(movement).Error; err != nil {
+		return err
+	}
+
+	ingredient.Stock = movement.StockAfter
+	ingredient.StockValue = value
+	return nil
+}
+
+// LatestMovementID returns the ID of the most recent ingredient movement, or 0
+// when the ledger is empty
+// It marks a point in the ledger like StockMovementRepository.LatestID
+func (r *IngredientRepository) LatestMovementID(tx *gorm.DB) (uint, error) {
+	var id uint
+	err := tx.Model(&model.IngredientMovement{}).Select("COALESCE(MAX(id), 0)").Scan(&id).Error
+	return id, err
+}
+
+// SumMovementsBetween returns the net quantity of the movements of an ingredient
+// with an ID after afterID and up to uptoID
+func (r *IngredientRepository) SumMovementsBetween(tx *gorm.DB, ingredientID, afterID, uptoID uint) (int, error) {
+	var sum int
+	err := tx.Model(&model.IngredientMovement{}).
+		Select("COALESCE(SUM(quantity), 0)").
+		Where("ingredient_id = ? AND id > ? AND id <= ?", ingredientID, afterID, uptoID).
+		Scan(&sum).Error
+	return sum, err
+}
+
+// IngredientMovementFilter holds the optional criteria for listing ingredient movements
+// Movements are listed newest first, with the same cursor semantics as StockMovementFilter
+type IngredientMovementFilter struct {
+	IngredientID uint
+	Type         string
+	From         *time.Time
+	To           *time.Time
+	Cursor       uint
+	Limit        int
+}
+
+// ListMovements retrieves the ledger of an ingredient matching the filter
+func (r *IngredientRepository) ListMovements(filter IngredientMovementFilter) ([]model.IngredientMovement, error) {
+	query := r.db.Where("ingredient_id = ?", filter.IngredientID)
+
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+	if filter.Cursor > 0 {
+		query = query.Where("id < ?", filter.Cursor)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	var movements []model.IngredientMovement
+	err := query.Order("id DESC").Find(&movements).Error
+	return movements, err
+}
+
+// GetSaleMovements retrieves the ingredient sale movements of the given transaction details
+func (r *IngredientRepository) GetSaleMovements(tx *gorm.DB, detailIDs []uint) ([]model.IngredientMovement, error) {
+	var movements []model.IngredientMovement
+	if len(detailIDs) == 0 {
+		return movements, nil
+	}
+	err := tx.Where("transaction_detail_id IN ? AND type = ?", detailIDs, model.StockMovementSale).
+		Order("id ASC").
+		Find(&movements).Error
+	return movements, err
+}
+
+// GetMenuRecipe retrieves the recipe of a menu item within a database transaction
+func (r *IngredientRepository) GetMenuRecipe(tx *gorm.DB, menuID uint) ([]model.MenuIngredient, error) {
+	var recipe []model.MenuIngredient
+	err := tx.Where("menu_id = ?", menuID).Order("id ASC").Find(&recipe).Error
+	return recipe, err
+}
+
+// GetOptionRecipes retrieves the recipes of the given modifier options within a database transaction
+func (r *IngredientRepository) GetOptionRecipes(tx *gorm.DB, optionIDs []uint) ([]model.ModifierOptionIngredient, error) {
+	var recipes []model.ModifierOptionIngredient
+	if len(optionIDs) == 0 {
+		return recipes, nil
+	}
+	err := tx.Where("modifier_option_id IN ?", optionIDs).Order("id ASC").Find(&recipes).Error
+	return recipes, err
+}
+
+// GetOptionRecipe retrieves the recipe of a modifier option with its ingredients
+func (r *IngredientRepository) GetOptionRecipe(optionID uint) ([]model.ModifierOptionIngredient, error) {
+	var recipe []model.ModifierOptionIngredient
+	err := r.db.Preload("Ingredient").Where("modifier_option_id = ?", optionID).Order("id ASC").Find(&recipe).Error
+	return recipe, err
+}
+
+// ReplaceMenuRecipe replaces the recipe of a menu item
+// An empty recipe turns the item back into one tracked by its own stock
+func (r *IngredientRepository) ReplaceMenuRecipe(menuID uint, recipe []model.MenuIngredient) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("menu_id = ?", menuID).Delete(&model.MenuIngredient{}).Error; err != nil {
+			return err
+		}
+		if len(recipe) == 0 {
+			return nil
+		}
+		for i := range recipe {
+			recipe[i].MenuID = menuID
+		}
+		return tx.Omit(clause.Associations).Create(&recipe).Error
+	})
+}
+
+// ReplaceOptionRecipe replaces the recipe of a modifier option
+func (r *IngredientRepository) ReplaceOptionRecipe(optionID uint, recipe []model.ModifierOptionIngredient) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("modifier_option_id = ?", optionID).Delete(&model.ModifierOptionIngredient{}).Error; err != nil {
+			return err
+		}
+		if len(recipe) == 0 {
+			return nil
+		}
+		for i := range recipe {
+			recipe[i].ModifierOptionID = optionID
+		}
+		return tx.Omit(clause.Associations).Create(&recipe).Error
+	})
+}
+
+// BeginTransaction starts a new database transaction
+func (r *IngredientRepository) BeginTransaction() *gorm.DB {
+	return r.db.Begin()
+}
@@ -2,6 +2,8 @@ package repository
 
 import (
 	"service-cashier/internal/model"
+	"service-cashier/pkg/money"
+	"strings"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -17,17 +19,145 @@ func NewMenuRepository(db *gorm.DB) *MenuRepository {
 	return &MenuRepository{db: db}
 }
 
-// GetAll retrieves all menu items
-func (r *MenuRepository) GetAll() ([]model.Menu, error) {
+// MenuFilter holds the optional criteria for listing menu items
+// SortBy is one of name, price, stock or created_at; when empty, items are ordered
+// by category display order and name. Offset and Limit select the page to return
+// Archived lists archived items instead of live ones; AvailableOnly excludes
+// disabled and sold-out items
+type MenuFilter struct {
+	Archived      bool
+	CategoryID    *uint
+	Search        string
+	InStockOnly   bool
+	AvailableOnly bool
+	MinPrice      *money.Money
+	MaxPrice      *money.Money
+	SortBy        string
+	SortDesc      bool
+	Offset        int
+	Limit         int
+}
+
+// menuSortColumns maps the sortable fields of the menu listing to their columns
+var menuSortColumns = map[string]string{
+	"name":       "menus.name",
+	"price":      "menus.price",
+	"stock":      "menus.stock",
+	"created_at": "menus.created_at",
+}
+
+// GetAll retrieves a page of menu items matching the filter with their category and modifiers
+// and the total number of matching items. Items in inactive categories are excluded
+func (r *MenuRepository) GetAll(filter MenuFilter) ([]model.Menu, int64, error) {
+	query := r.db.Model(&model.Menu{}).
+		Joins("LEFT JOIN categories ON categories.id = menus.category_id").
+		Where("categories.id IS NULL OR categories.active = ?", true)
+
+	if filter.Archived {
+		query = query.Unscoped().Where("menus.deleted_at IS NOT NULL")
+	}
+
+	if filter.CategoryID != nil {
+		query = query.Where("menus.category_id = ?", *filter.CategoryID)
+	}
+	if filter.Search != "" {
+		query = query.Where("menus.name LIKE ?", "%"+escapeLike(filter.Search)+"%")
+	}
+	if filter.InStockOnly {
+		query = query.Where(menuInStockSQL)
+	}
+	if filter.AvailableOnly {
+		query = query.Where(menuInStockSQL).Where("menus.disabled = ?", false)
+	}
+	if filter.MinPrice != nil {
+		query = query.Where("menus.price >= ?", *filter.MinPrice)
+	}
+	if filter.MaxPrice != nil {
+		query = query.Where("menus.price <= ?", *filter.MaxPrice)
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if column, ok := menuSortColumns[filter.SortBy]; ok {
+		direction := " ASC"
+		if filter.SortDesc {
+			direction = " DESC"
+		}
+		query = query.Order(column + direction).Order("menus.id" + direction)
+	} else {
+		// Uncategorized items are listed last
+		query = query.Order("categories.id IS NULL, categories.display_order ASC, categories.name ASC, menus.name ASC")
+	}
+
 	var menus []model.Menu
-	err := r.db.Find(&menus).Error
-	return menus, err
+	err := query.Offset(filter.Offset).Limit(filter.Limit).
+		Scopes(preloadModifiers, preloadRecipe).Preload("Category").Find(&menus).Error
+	return menus, total, err
 }
 
-// FindByID retrieves a menu item by ID
+// menuInStockSQL matches menu items that can sell at least one unit: items
+// without a recipe need stock of their own, items with a recipe need enough of
+// every ingredient for one portion
+const menuInStockSQL = `(
+	(NOT EXISTS (SELECT 1 FROM menu_ingredients mi WHERE mi.menu_id = menus.id) AND menus.stock > 0)
+	OR (EXISTS (SELECT 1 FROM menu_ingredients mi WHERE mi.menu_id = menus.id)
+		AND NOT EXISTS (SELECT 1 FROM menu_ingredients mi
+			JOIN ingredients i ON i.id = mi.ingredient_id
+			WHERE mi.menu_id = menus.id AND i.stock < mi.quantity))
+)`
+
+// preloadRecipe loads the recipe of menu items with the current ingredient levels
+func preloadRecipe(db *gorm.DB) *gorm.DB {
+	return db.Preload("Recipe", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id ASC")
+	}).Preload("Recipe.Ingredient")
+}
+
+// preloadModifiers loads the active modifier groups and options of menu items
+func preloadModifiers(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("ModifierGroups", "active = ?", true).
+		Preload("ModifierGroups.Options", func(db *gorm.DB) *gorm.DB {
+			return db.Where("active = ?", true).Order("display_order ASC, id ASC")
+		})
+}
+
+// escapeLike escapes the wildcard characters of a LIKE pattern
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+// FindByID retrieves a menu item by ID with its category and modifiers
 func (r *MenuRepository) FindByID(id uint) (*model.Menu, error) {
 	var menu model.Menu
-	err := r.db.First(&menu, id).Error
+	err := r.db.Scopes(preloadModifiers, preloadRecipe).Preload("Category").First(&menu, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &menu, nil
+}
+
+// GetLowStock retrieves the live menu items at or below their reorder threshold,
+// lowest stock first
+// Items without a threshold are included once they sell out. Items with a recipe
+// are left out; the low-stock report lists their ingredients instead
+func (r *MenuRepository) GetLowStock() ([]model.Menu, error) {
+	var menus []model.Menu
+	err := r.db.Preload("Category").
+		Where("stock <= reorder_threshold").
+		Where("NOT EXISTS (SELECT 1 FROM menu_ingredients mi WHERE mi.menu_id = menus.id)").
+		Order("stock ASC, name ASC").
+		Find(&menus).Error
+	return menus, err
+}
+
+// FindByIDUnscoped retrieves a menu item by ID including archived items
+func (r *MenuRepository) FindByIDUnscoped(id uint) (*model.Menu, error) {
+	var menu model.Menu
+	err := r.db.Unscoped().First(&menu, id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -36,6 +166,7 @@ func (r *MenuRepository) FindByID(id uint) (*model.Menu, error) {
 
 // FindByIDWithLock retrieves a menu item by ID with row-level locking for updates
 // This is used during checkout to prevent race conditions when updating stock
+// Archived menu items are not found
 func (r *MenuRepository) FindByIDWithLock(tx *gorm.DB, id uint) (*model.Menu, error) {
 	var menu model.Menu
 	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&menu, id).Error
@@ -45,22 +176,123 @@ func (r *MenuRepository) FindByIDWithLock(tx *gorm.DB, id uint) (*model.Menu, er
 	return &menu, nil
 }
 
-// Create creates a new menu item
-func (r *MenuRepository) Create(menu *model.Menu) error {
-	return r.db.Create(menu).Error
+// LockByIDs takes row-level locks on the live menu items with the given IDs
+// Rows are locked in ID order, like every other path that locks several menu
+// items, so concurrent checkouts, refunds and stock takes cannot deadlock
+func (r *MenuRepository) LockByIDs(tx *gorm.DB, ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	var locked []uint
+	return tx.Model(&model.Menu{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id IN ?", ids).
+		Order("id ASC").
+		Pluck("id", &locked).Error
+}
+
+// FindByIDWithLockUnscoped retrieves a menu item by ID with row-level locking,
+// including archived items
+// This is used when returning stock of items sold before they were archived
+func (r *MenuRepository) FindByIDWithLockUnscoped(tx *gorm.DB, id uint) (*model.Menu, error) {
+	var menu model.Menu
+	err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).First(&menu, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &menu, nil
+}
+
+// Create creates a new menu item within a database transaction
+// Associations are managed separately and are not written
+func (r *MenuRepository) Create(tx *gorm.DB, menu *model.Menu) error {
+	return tx.Omit(clause.Associations).Create(menu).Error
+}
+
+// Update updates an existing menu item within a database transaction
+// Associations are managed separately, and stock only changes through ApplyStockMovement
+func (r *MenuRepository) Update(tx *gorm.DB, menu *model.Menu) error {
+	return tx.Omit(clause.Associations, "stock").Save(menu).Error
+}
+
+// ReplaceModifierGroups sets the modifier groups attached to a menu item
+func (r *MenuRepository) ReplaceModifierGroups(menu *model.Menu, groups []model.ModifierGroup) error {
+	return r.db.Model(menu).Association("ModifierGroups").Replace(groups)
+}
+
+// ApplyStockMovement changes the stock of a menu item by movement.Quantity and
+// appends the movement to the stock ledger within a database transaction
+// Units received with a unit cost move the item's CostPrice to the new weighted average.
+// The menu row must already be locked by the caller; menu.Stock and menu.CostPrice
+// are updated to the new level.
+// Archived items are included so that refunds can return their stock
+func (r *MenuRepository) ApplyStockMovement(tx *gorm.DB, menu *model.Menu, movement *model.StockMovement) error {
+	movement.MenuID = menu.ID
+	movement.StockBefore = menu.Stock
+	movement.StockAfter = menu.Stock + movement.Quantity
+
+	costPrice := menu.CostPrice
+	if movement.Quantity > 0 && movement.UnitCost != nil {
+		costPrice = menu.AverageCostAfter(movement.Quantity, *movement.UnitCost)
+	}
+
+	err := tx.Unscoped().Model(&model.Menu{}).Where("id = ?", menu.ID).Updates(map[string]interface{}{
+		"stock":      movement.StockAfter,
+		"cost_price": costPrice,
+	}).Error
+	if err != nil {
+		return err
+	}
+	if err := tx.Create(movement).Error; err != nil {
+		return err
+	}
+
+	menu.Stock = movement.StockAfter
+	menu.CostPrice = costPrice
+	menu.RefreshAvailability()
+	return nil
 }
 
-// Update updates an existing menu item
-func (r *MenuRepository) Update(menu *model.Menu) error {
-	return r.db.Save(menu).Error
+// FindStockTrackedWithLock retrieves live menu items without a recipe, with
+// row-level locking in ID order; an empty ids list selects every such item
+func (r *MenuRepository) FindStockTrackedWithLock(tx *gorm.DB, ids []uint) ([]model.Menu, error) {
+	var menus []model.Menu
+	query := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("NOT EXISTS (SELECT 1 FROM menu_ingredients mi WHERE mi.menu_id = menus.id)")
+	if len(ids) > 0 {
+		query = query.Where("id IN ?", ids)
+	}
+	err := query.Order("id ASC").Find(&menus).Error
+	return menus, err
+}
+
+// SetDisabled takes a menu item off sale or puts it back, without touching stock
+func (r *MenuRepository) SetDisabled(id uint, disabled bool) error {
+	return r.db.Model(&model.Menu{}).Where("id = ?", id).Update("disabled", disabled).Error
 }
 
-// UpdateStock updates the stock of a menu item within a transaction
-func (r *MenuRepository) UpdateStock(tx *gorm.DB, menuID uint, newStock int) error {
-	return tx.Model(&model.Menu{}).Where("id = ?", menuID).Update("stock", newStock).Error
+// BeginTransaction starts a new database transaction
+func (r *MenuRepository) BeginTransaction() *gorm.DB {
+	return r.db.Begin()
 }
 
-// Delete deletes a menu item by ID
+// Delete archives a menu item by ID
+// The item keeps its category and modifier groups so that it can be restored
 func (r *MenuRepository) Delete(id uint) error {
 	return r.db.Delete(&model.Menu{}, id).Error
 }
+
+// Restore brings an archived menu item back
+func (r *MenuRepository) Restore(id uint) error {
+	return r.db.Unscoped().Model(&model.Menu{}).Where("id = ?", id).Update("deleted_at", nil).Error
+}
+
+// ExistsByName checks whether another menu item already uses the given name
+// excludeID allows the menu being updated to keep its own name
+func (r *MenuRepository) ExistsByName(name string, excludeID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.Menu{}).
+		Where("LOWER(name) = LOWER(?) AND id <> ?", name, excludeID).
+		Count(&count).Error
+	return count > 0, err
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"service-cashier/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// ModifierRepository handles modifier group and option data access operations
+type ModifierRepository struct {
+	db *gorm.DB
+}
+
+// NewModifierRepository creates a new ModifierRepository instance
+func NewModifierRepository(db *gorm.DB) *ModifierRepository {
+	return &ModifierRepository{db: db}
+}
+
+// GetAll retrieves all modifier groups with their options
+func (r *ModifierRepository) GetAll() ([]model.ModifierGroup, error) {
+	var groups []model.ModifierGroup
+	err := r.db.
+		Preload("Options", func(db *gorm.DB) *gorm.DB {
+			return db.Order("display_order ASC, id ASC")
+		}).
+		Order("name ASC").
+		Find(&groups).Error
+	return groups, err
+}
+
+// FindByID retrieves a modifier group by ID with its options
+func (r *ModifierRepository) FindByID(id uint) (*model.ModifierGroup, error) {
+	var group model.ModifierGroup
+	err := r.db.
+		Preload("Options", func(db *gorm.DB) *gorm.DB {
+			return db.Order("display_order ASC, id ASC")
+		}).
+		First(&group, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+// FindByIDs retrieves the modifier groups with the given IDs
+func (r *ModifierRepository) FindByIDs(ids []uint) ([]model.ModifierGroup, error) {
+	var groups []model.ModifierGroup
+	if len(ids) == 0 {
+		return groups, nil
+	}
+	err := r.db.Where("id IN ?", ids).Find(&groups).Error
+	return groups, err
+}
+
+// FindOptionByID retrieves a modifier option by ID
+func (r *ModifierRepository) FindOptionByID(id uint) (*model.ModifierOption, error) {
+	var option model.ModifierOption
+	err := r.db.First(&option, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &option, nil
+}
+
+// GetActiveByMenuID retrieves the active modifier groups attached to a menu item
+// with their active options within a database transaction
+func (r *ModifierRepository) GetActiveByMenuID(tx *gorm.DB, menuID uint) ([]model.ModifierGroup, error) {
+	var groups []model.ModifierGroup
+	err := tx.
+		Joins("JOIN menu_modifier_groups ON menu_modifier_groups.modifier_group_id = modifier_groups.id").
+		Where("menu_modifier_groups.menu_id = ? AND modifier_groups.active = ?", menuID, true).
+		Preload("Options", "active = ?", true).
+		Order("modifier_groups.id ASC").
+		Find(&groups).Error
+	return groups, err
+}
+
+// Create creates a new modifier group together with its options
+func (r *ModifierRepository) Create(group *model.ModifierGroup) error {
+	return r.db.Create(group).Error
+}
+
+// Update saves a modifier group and replaces its options
+// Options keep their IDs when present in group.Options; options no longer listed are deleted
+func (r *ModifierRepository) Update(group *model.ModifierGroup) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Options").Save(group).Error; err != nil {
+			return err
+		}
+
+		keep := make([]uint, 0, len(group.Options))
+		for i := range group.Options {
+			group.Options[i].ModifierGroupID = group.ID
+			if err := tx.Save(&group.Options[i]).Error; err != nil {
+				return err
+			}
+			keep = append(keep, group.Options[i].ID)
+		}
+
+		// Removed options take their recipes with them
+		removed := tx.Model(&model.ModifierOption{}).Select("id").
+			Where("modifier_group_id = ? AND id NOT IN ?", group.ID, keep)
+		if err := tx.Where("modifier_option_id IN (?)", removed).Delete(&model.ModifierOptionIngredient{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("modifier_group_id = ? AND id NOT IN ?", group.ID, keep).
+			Delete(&model.ModifierOption{}).Error
+	})
+}
+
+// Delete deletes a modifier group, its options with their recipes and its menu assignments
+func (r *ModifierRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM menu_modifier_groups WHERE modifier_group_id = ?", id).Error; err != nil {
+			return err
+		}
+		options := tx.Model(&model.ModifierOption{}).Select("id").Where("modifier_group_id = ?", id)
+		if err := tx.Where("modifier_option_id IN (?)", options).Delete(&model.ModifierOptionIngredient{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("modifier_group_id = ?", id).Delete(&model.ModifierOption{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.ModifierGroup{}, id).Error
+	})
+}
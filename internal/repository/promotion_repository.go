@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"service-cashier/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PromotionRepository handles promotion data access operations
+type PromotionRepository struct {
+	db *gorm.DB
+}
+
+// NewPromotionRepository creates a new PromotionRepository instance
+func NewPromotionRepository(db *gorm.DB) *PromotionRepository {
+	return &PromotionRepository{db: db}
+}
+
+// GetAll retrieves all promotions, newest first
+func (r *PromotionRepository) GetAll() ([]model.Promotion, error) {
+	var promotions []model.Promotion
+	err := r.db.Order("created_at DESC").Find(&promotions).Error
+	return promotions, err
+}
+
+// FindByID retrieves a promotion by ID
+func (r *PromotionRepository) FindByID(id uint) (*model.Promotion, error) {
+	var promotion model.Promotion
+	err := r.db.First(&promotion, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &promotion, nil
+}
+
+// FindByCodeWithLock retrieves a promotion by code with row-level locking
+// This is used during checkout so usage limits cannot be exceeded by concurrent redemptions
+func (r *PromotionRepository) FindByCodeWithLock(tx *gorm.DB, code string) (*model.Promotion, error) {
+	var promotion model.Promotion
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("code = ?", code).First(&promotion).Error
+	if err != nil {
+		return nil, err
+	}
+	return &promotion, nil
+}
+
+// IncrementUsage increases the usage count of a promotion within a database transaction
+func (r *PromotionRepository) IncrementUsage(tx *gorm.DB, id uint) error {
+	return tx.Model(&model.Promotion{}).Where("id = ?", id).
+		Update("usage_count", gorm.Expr("usage_count + 1")).Error
+}
+
+// FindByIDWithLock retrieves a promotion by ID with row-level locking
+func (r *PromotionRepository) FindByIDWithLock(tx *gorm.DB, id uint) (*model.Promotion, error) {
+	var promotion model.Promotion
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&promotion, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &promotion, nil
+}
+
+// DecrementUsage gives back one use of a promotion within a database transaction
+func (r *PromotionRepository) DecrementUsage(tx *gorm.DB, id uint) error {
+	return tx.Model(&model.Promotion{}).Where("id = ? AND usage_count > 0", id).
+		Update("usage_count", gorm.Expr("usage_count - 1")).Error
+}
+
+// Create creates a new promotion
+func (r *PromotionRepository) Create(promotion *model.Promotion) error {
+	return r.db.Create(promotion).Error
+}
+
+// Update updates an existing promotion
+func (r *PromotionRepository) Update(promotion *model.Promotion) error {
+	return r.db.Save(promotion).Error
+}
+
+// Delete deletes a promotion by ID
+func (r *PromotionRepository) Delete(id uint) error {
+	return r.db.Delete(&model.Promotion{}, id).Error
+}
+
+// IsRedeemed checks whether a promotion has been applied to any transaction
+func (r *PromotionRepository) IsRedeemed(id uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.TransactionDiscount{}).Where("promotion_id = ?", id).Count(&count).Error
+	return count > 0, err
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"service-cashier/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PurchaseOrderRepository handles purchase order data access operations
+type PurchaseOrderRepository struct {
+	db *gorm.DB
+}
+
+// NewPurchaseOrderRepository creates a new PurchaseOrderRepository instance
+func NewPurchaseOrderRepository(db *gorm.DB) *PurchaseOrderRepository {
+	return &PurchaseOrderRepository{db: db}
+}
+
+// PurchaseOrderFilter holds the optional criteria for listing purchase orders
+type PurchaseOrderFilter struct {
+	Status     string
+	SupplierID uint
+}
+
+// GetAll retrieves purchase orders matching the filter newest first, with their suppliers
+// Lines are not loaded
+func (r *PurchaseOrderRepository) GetAll(filter PurchaseOrderFilter) ([]model.PurchaseOrder, error) {
+	query := r.db.Preload("Supplier").Order("id DESC")
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.SupplierID > 0 {
+		query = query.Where("supplier_id = ?", filter.SupplierID)
+	}
+
+	var orders []model.PurchaseOrder
+	err := query.Find(&orders).Error
+	return orders, err
+}
+
+// FindByID retrieves a purchase order by ID with its supplier and lines
+func (r *PurchaseOrderRepository) FindByID(id uint) (*model.PurchaseOrder, error) {
+	var order model.PurchaseOrder
+	err := r.db.
+		Preload("Supplier").
+		Preload("Lines", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id ASC")
+		}).
+		First(&order, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// FindByIDWithLock retrieves a purchase order by ID with its lines under row-level locks
+func (r *PurchaseOrderRepository) FindByIDWithLock(tx *gorm.DB, id uint) (*model.PurchaseOrder, error) {
+	var order model.PurchaseOrder
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, id).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("purchase_order_id = ?", id).
+		Order("id ASC").
+		Find(&order.Lines).Error
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// Create creates a purchase order together with its lines within a database transaction
+func (r *PurchaseOrderRepository) Create(tx *gorm.DB, order *model.PurchaseOrder) error {
+	return tx.Omit("Supplier").Create(order).Error
+}
+
+// Update saves the header of a purchase order within a database transaction
+func (r *PurchaseOrderRepository) Update(tx *gorm.DB, order *model.PurchaseOrder) error {
+	return tx.Omit(clause.Associations).Save(order).Error
+}
+
+// ReplaceLines replaces the lines of a purchase order within a database transaction
+func (r *PurchaseOrderRepository) ReplaceLines(tx *gorm.DB, orderID uint, lines []model.PurchaseOrderLine) error {
+	if err := tx.Where("purchase_order_id = ?", orderID).Delete(&model.PurchaseOrderLine{}).Error; err != nil {
+		return err
+	}
+	for i := range lines {
+		lines[i].ID = 0
+		lines[i].PurchaseOrderID = orderID
+	}
+	return tx.Create(&lines).Error
+}
+
+// UpdateLine saves a purchase order line within a database transaction
+func (r *PurchaseOrderRepository) UpdateLine(tx *gorm.DB, line *model.PurchaseOrderLine) error {
+	return tx.Save(line).Error
+}
+
+// Delete deletes a purchase order and its lines within a database transaction
+func (r *PurchaseOrderRepository) Delete(tx *gorm.DB, id uint) error {
+	if err := tx.Where("purchase_order_id = ?", id).Delete(&model.PurchaseOrderLine{}).Error; err != nil {
+		return err
+	}
+	return tx.Delete(&model.PurchaseOrder{}, id).Error
+}
+
+// BeginTransaction starts a new database transaction
+func (r *PurchaseOrderRepository) BeginTransaction() *gorm.DB {
+	return r.db.Begin()
+}
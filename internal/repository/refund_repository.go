@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"service-cashier/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// RefundRepository handles refund and void data access operations
+type RefundRepository struct {
+	db *gorm.DB
+}
+
+// NewRefundRepository creates a new RefundRepository instance
+func NewRefundRepository(db *gorm.DB) *RefundRepository {
+	return &RefundRepository{db: db}
+}
+
+// Create creates a refund document and its items within a database transaction
+func (r *RefundRepository) Create(tx *gorm.DB, refund *model.Refund) error {
+	return tx.Create(refund).Error
+}
+
+// GetByTransactionID retrieves all refunds of a transaction with their items
+func (r *RefundRepository) GetByTransactionID(transactionID uint) ([]model.Refund, error) {
+	var refunds []model.Refund
+	err := r.db.
+		Where("transaction_id = ?", transactionID).
+		Preload("Items").
+		Preload("ApprovedBy").
+		Order("created_at ASC").
+		Find(&refunds).Error
+	return refunds, err
+}
+
+// GetTypesByTransactionID returns the type of every void and refund already
+// recorded against a transaction, oldest first, within a database transaction
+func (r *RefundRepository) GetTypesByTransactionID(tx *gorm.DB, transactionID uint) ([]string, error) {
+	var types []string
+	err := tx.Model(&model.Refund{}).
+		Where("transaction_id = ?", transactionID).
+		Order("id ASC").
+		Pluck("type", &types).Error
+	return types, err
+}
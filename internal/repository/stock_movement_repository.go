@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"service-cashier/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// StockMovementRepository handles stock movement ledger data access operations
+type StockMovementRepository struct {
+	db *gorm.DB
+}
+
+// NewStockMovementRepository creates a new StockMovementRepository instance
+func NewStockMovementRepository(db *gorm.DB) *StockMovementRepository {
+	return &StockMovementRepository{db: db}
+}
+
+// StockMovementFilter holds the optional criteria for listing stock movements
+// Movements are listed newest first. Cursor is the ID of the last movement of
+// the previous page, and only older movements are returned after it
+type StockMovementFilter struct {
+	MenuID uint
+	Type   string
+	From   *time.Time
+	To     *time.Time
+	Cursor uint
+	Limit  int
+}
+
+// List retrieves the stock movements of a menu item matching the filter
+func (r *StockMovementRepository) List(filter StockMovementFilter) ([]model.StockMovement, error) {
+	query := r.db.Where("menu_id = ?", filter.MenuID)
+
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+	if filter.Cursor > 0 {
+		query = query.Where("id < ?", filter.Cursor)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	var movements []model.StockMovement
+	err := query.Order("id DESC").Find(&movements).Error
+	return movements, err
+}
+
+// LatestID returns the ID of the most recent stock movement, or 0 when the ledger is empty
+// Read after locking the menu rows of interest, it marks a point in the ledger
+// that every later movement of those items comes after
+func (r *StockMovementRepository) LatestID(tx *gorm.DB) (uint, error) {
+	var id uint
+	err := tx.Model(&model.StockMovement{}).Select("COALESCE(MAX(id), 0)").Scan(&id).Error
+	return id, err
+}
+
+// SumBetween returns the net quantity of the stock movements of a menu item
+// with an ID after afterID and up to uptoID
+func (r *StockMovementRepository) SumBetween(tx *gorm.DB, menuID, afterID, uptoID uint) (int, error) {
+	var sum int
+	err := tx.Model(&model.StockMovement{}).
+		Select("COALESCE(SUM(quantity), 0)").
+		Where("menu_id = ? AND id > ? AND id <= ?", menuID, afterID, uptoID).
+		Scan(&sum).Error
+	return sum, err
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"service-cashier/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// StockTakeRepository handles stock take data access operations
+type StockTakeRepository struct {
+	db *gorm.DB
+}
+
+// NewStockTakeRepository creates a new StockTakeRepository instance
+func NewStockTakeRepository(db *gorm.DB) *StockTakeRepository {
+	return &StockTakeRepository{db: db}
+}
+
+// GetAll retrieves stock takes newest first, optionally with the given status
+// Lines are not loaded
+func (r *StockTakeRepository) GetAll(status string) ([]model.StockTake, error) {
+	var stockTakes []model.StockTake
+	query := r.db.Order("id DESC")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Find(&stockTakes).Error
+	return stockTakes, err
+}
+
+// stockTakeLineOrder lists menu item lines by menu ID, then ingredient lines by
+// ingredient ID, the order in which their rows are locked
+const stockTakeLineOrder = "menu_id IS NULL, menu_id ASC, ingredient_id ASC"
+
+// FindByID retrieves a stock take by ID with its lines
+func (r *StockTakeRepository) FindByID(id uint) (*model.StockTake, error) {
+	var stockTake model.StockTake
+	err := r.db.
+		Preload("Lines", func(db *gorm.DB) *gorm.DB {
+			return db.Order(stockTakeLineOrder)
+		}).
+		First(&stockTake, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &stockTake, nil
+}
+
+// FindByIDWithLock retrieves a stock take by ID with its lines under row-level locks
+func (r *StockTakeRepository) FindByIDWithLock(tx *gorm.DB, id uint) (*model.StockTake, error) {
+	var stockTake model.StockTake
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&stockTake, id).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("stock_take_id = ?", id).
+		Order(stockTakeLineOrder).
+		Find(&stockTake.Lines).Error
+	if err != nil {
+		return nil, err
+	}
+	return &stockTake, nil
+}
+
+// Create creates a stock take together with its lines within a database transaction
+func (r *StockTakeRepository) Create(tx *gorm.DB, stockTake *model.StockTake) error {
+	return tx.Create(stockTake).Error
+}
+
+// Update saves the status fields of a stock take within a database transaction
+func (r *StockTakeRepository) Update(tx *gorm.DB, stockTake *model.StockTake) error {
+	return tx.Omit("Lines").Save(stockTake).Error
+}
+
+// UpdateLine saves a stock take line within a database transaction
+func (r *StockTakeRepository) UpdateLine(tx *gorm.DB, line *model.StockTakeLine) error {
+	return tx.Save(line).Error
+}
+
+// BeginTransaction starts a new database transaction
+func (r *StockTakeRepository) BeginTransaction() *gorm.DB {
+	return r.db.Begin()
+}
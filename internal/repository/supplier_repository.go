@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"service-cashier/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// SupplierRepository handles supplier data access operations
+type SupplierRepository struct {
+	db *gorm.DB
+}
+
+// NewSupplierRepository creates a new SupplierRepository instance
+func NewSupplierRepository(db *gorm.DB) *SupplierRepository {
+	return &SupplierRepository{db: db}
+}
+
+// GetAll retrieves suppliers ordered by name
+// activeOnly hides suppliers that were deactivated
+func (r *SupplierRepository) GetAll(activeOnly bool) ([]model.Supplier, error) {
+	var suppliers []model.Supplier
+	query := r.db.Order("name ASC")
+	if activeOnly {
+		query = query.Where("active = ?", true)
+	}
+	err := query.Find(&suppliers).Error
+	return suppliers, err
+}
+
+// FindByID retrieves a supplier by ID
+func (r *SupplierRepository) FindByID(id uint) (*model.Supplier, error) {
+	var supplier model.Supplier
+	err := r.db.First(&supplier, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &supplier, nil
+}
+
+// Create creates a new supplier
+func (r *SupplierRepository) Create(supplier *model.Supplier) error {
+	return r.db.Create(supplier).Error
+}
+
+// Update updates an existing supplier
+func (r *SupplierRepository) Update(supplier *model.Supplier) error {
+	return r.db.Save(supplier).Error
+}
+
+// HasPurchaseOrders checks whether any purchase order was raised with the supplier
+func (r *SupplierRepository) HasPurchaseOrders(id uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.PurchaseOrder{}).Where("supplier_id = ?", id).Count(&count).Error
+	return count > 0, err
+}
+
+// Delete deletes a supplier by ID
+func (r *SupplierRepository) Delete(id uint) error {
+	return r.db.Delete(&model.Supplier{}, id).Error
+}
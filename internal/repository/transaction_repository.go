@@ -2,8 +2,11 @@ package repository
 
 import (
 	"service-cashier/internal/model"
+	"service-cashier/pkg/money"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // TransactionRepository handles transaction data access operations
@@ -31,40 +34,424 @@ func (r *TransactionRepository) CreateDetails(tx *gorm.DB, details []model.Trans
 	return tx.Create(&details).Error
 }
 
-// FindByID retrieves a transaction by ID with its details
+// CreateDetailModifiers records the modifiers chosen for transaction details within a database transaction
+func (r *TransactionRepository) CreateDetailModifiers(tx *gorm.DB, modifiers []model.TransactionDetailModifier) error {
+	if len(modifiers) == 0 {
+		return nil
+	}
+	return tx.Create(&modifiers).Error
+}
+
+// CreatePayments creates the payment lines of a transaction within a database transaction
+func (r *TransactionRepository) CreatePayments(tx *gorm.DB, payments []model.Payment) error {
+	return tx.Create(&payments).Error
+}
+
+// CreateDiscounts records the discounts applied to a transaction within a database transaction
+func (r *TransactionRepository) CreateDiscounts(tx *gorm.DB, discounts []model.TransactionDiscount) error {
+	if len(discounts) == 0 {
+		return nil
+	}
+	return tx.Create(&discounts).Error
+}
+
+// FindByID retrieves a transaction by ID with its details and payments
 func (r *TransactionRepository) FindByID(id uint) (*model.Transaction, error) {
 	var transaction model.Transaction
-	err := r.db.Preload("Details").Preload("Details.Menu").First(&transaction, id).Error
+	err := r.db.Preload("Details.Modifiers").Preload("Payments").Preload("Discounts").First(&transaction, id).Error
 	if err != nil {
 		return nil, err
 	}
 	return &transaction, nil
 }
 
-// GetByCashierID retrieves all transactions for a specific cashier with details
-func (r *TransactionRepository) GetByCashierID(cashierID uint) ([]model.Transaction, error) {
-	var transactions []model.Transaction
+// FindByIDWithLock retrieves a transaction and its details with row-level locking
+// This is used when refunding to prevent two refunds of the same items from racing
+func (r *TransactionRepository) FindByIDWithLock(tx *gorm.DB, id uint) (*model.Transaction, error) {
+	var transaction model.Transaction
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&transaction, id).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("transaction_id = ?", id).
+		Order("id ASC").
+		Find(&transaction.Details).Error
+	if err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+// GetPromotionIDs returns the IDs of the promotions redeemed by a transaction in ascending order
+func (r *TransactionRepository) GetPromotionIDs(tx *gorm.DB, transactionID uint) ([]uint, error) {
+	var ids []uint
+	err := tx.Model(&model.TransactionDiscount{}).
+		Where("transaction_id = ? AND promotion_id IS NOT NULL", transactionID).
+		Distinct().
+		Order("promotion_id ASC").
+		Pluck("promotion_id", &ids).Error
+	return ids, err
+}
+
+// UpdateRefundState saves the refund status and refunded amount of a transaction within a database transaction
+func (r *TransactionRepository) UpdateRefundState(tx *gorm.DB, transaction *model.Transaction) error {
+	return tx.Model(transaction).Updates(map[string]interface{}{
+		"status":          transaction.Status,
+		"refunded_amount": transaction.RefundedAmount,
+	}).Error
+}
+
+// UpdateDetailRefundState saves the refunded quantity and amount of a transaction detail within a database transaction
+func (r *TransactionRepository) UpdateDetailRefundState(tx *gorm.DB, detail *model.TransactionDetail) error {
+	return tx.Model(detail).Updates(map[string]interface{}{
+		"refunded_qty":    detail.RefundedQty,
+		"refunded_amount": detail.RefundedAmount,
+	}).Error
+}
+
+// FindByIdempotencyKey retrieves a cashier's transaction created with the given idempotency key
+func (r *TransactionRepository) FindByIdempotencyKey(cashierID uint, key string) (*model.Transaction, error) {
+	var transaction model.Transaction
 	err := r.db.
-		Where("cashier_id = ?", cashierID).
-		Preload("Details").
-		Preload("Details.Menu").
-		Order("created_at DESC").
-		Find(&transactions).Error
-	return transactions, err
+		Where("cashier_id = ? AND idempotency_key = ?", cashierID, key).
+		Preload("Details.Modifiers").
+		Preload("Payments").
+		Preload("Discounts").
+		First(&transaction).Error
+	if err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+// TransactionFilter holds the optional criteria for listing transactions
+// Transactions are listed newest first. Cursor is the ID of the last transaction
+// of the previous page, and only older transactions are returned after it
+type TransactionFilter struct {
+	CashierID      *uint
+	From           *time.Time
+	To             *time.Time
+	MinAmount      *money.Money
+	MaxAmount      *money.Money
+	PaymentMethod  string
+	Status         string
+	Cursor         uint
+	Limit          int
+	IncludeCashier bool
+}
+
+// TransactionTotals aggregates the amounts of the transactions matching a filter
+// NetAmount is the grand total less everything voided or refunded
+type TransactionTotals struct {
+	Count          int64       `json:"count"`
+	Subtotal       money.Money `json:"subtotal"`
+	DiscountAmount money.Money `json:"discount_amount"`
+	ServiceCharge  money.Money `json:"service_charge"`
+	TaxAmount      money.Money `json:"tax_amount"`
+	TotalAmount    money.Money `json:"total_amount"`
+	RefundedAmount money.Money `json:"refunded_amount"`
+	NetAmount      money.Money `json:"net_amount"`
+}
+
+// filtered applies the matching criteria of the filter, without cursor or limit
+func (r *TransactionRepository) filtered(filter TransactionFilter) *gorm.DB {
+	query := r.db.Model(&model.Transaction{})
+
+	if filter.CashierID != nil {
+		query = query.Where("transactions.cashier_id = ?", *filter.CashierID)
+	}
+	if filter.From != nil {
+		query = query.Where("transactions.created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("transactions.created_at < ?", *filter.To)
+	}
+	if filter.MinAmount != nil {
+		query = query.Where("transactions.total_amount >= ?", *filter.MinAmount)
+	}
+	if filter.MaxAmount != nil {
+		query = query.Where("transactions.total_amount <= ?", *filter.MaxAmount)
+	}
+	if filter.PaymentMethod != "" {
+		query = query.Where("EXISTS (SELECT 1 FROM transaction_payments WHERE transaction_payments.transaction_id = transactions.id AND transaction_payments.method = ?)", filter.PaymentMethod)
+	}
+	if filter.Status != "" {
+		query = query.Where("transactions.status = ?", filter.Status)
+	}
+	return query
 }
 
-// GetAll retrieves all transactions with details
-func (r *TransactionRepository) GetAll() ([]model.Transaction, error) {
+// List retrieves transactions matching the filter with their details, payments and discounts
+// Up to filter.Limit transactions are returned; a Limit of 0 returns every match
+func (r *TransactionRepository) List(filter TransactionFilter) ([]model.Transaction, error) {
+	query := r.filtered(filter)
+
+	if filter.Cursor > 0 {
+		query = query.Where("transactions.id < ?", filter.Cursor)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	if filter.IncludeCashier {
+		// Archived cashiers are still shown on the transactions they rang up
+		query = query.Preload("Cashier", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped()
+		})
+	}
+
 	var transactions []model.Transaction
-	err := r.db.
-		Preload("Details").
-		Preload("Details.Menu").
-		Preload("Cashier").
-		Order("created_at DESC").
+	err := query.
+		Preload("Details.Modifiers").
+		Preload("Payments").
+		Preload("Discounts").
+		Order("transactions.id DESC").
 		Find(&transactions).Error
 	return transactions, err
 }
 
+// Totals aggregates every transaction matching the filter, ignoring cursor and limit
+func (r *TransactionRepository) Totals(filter TransactionFilter) (TransactionTotals, error) {
+	var totals TransactionTotals
+	err := r.filtered(filter).
+		Select(`COUNT(*) AS count,
+			COALESCE(SUM(transactions.subtotal), 0) AS subtotal,
+			COALESCE(SUM(transactions.discount_amount), 0) AS discount_amount,
+			COALESCE(SUM(transactions.service_charge), 0) AS service_charge,
+			COALESCE(SUM(transactions.tax_amount), 0) AS tax_amount,
+			COALESCE(SUM(transactions.total_amount), 0) AS total_amount,
+			COALESCE(SUM(transactions.refunded_amount), 0) AS refunded_amount`).
+		Scan(&totals).Error
+	totals.NetAmount = totals.TotalAmount.Sub(totals.RefundedAmount)
+	return totals, err
+}
+
+// Sales margin groupings
+const (
+	MarginByItem     = "item"
+	MarginByCategory = "category"
+	MarginByDay      = "day"
+)
+
+// SalesMarginFilter holds the criteria of the sales margin report
+// Voided transactions and refunded units are left out
+type SalesMarginFilter struct {
+	From    *time.Time
+	To      *time.Time
+	GroupBy string
+}
+
+// SalesMarginRow aggregates the units sold, net sales and cost of goods of one
+// menu item, category or day, depending on the grouping
+// NetSales is what the lines were sold for after discounts, excluding tax and
+// service charge. CostAmount is the cost of goods snapshotted at sale time
+type SalesMarginRow struct {
+	MenuID      *uint       `json:"menu_id,omitempty"`
+	CategoryID  *uint       `json:"category_id,omitempty"`
+	Date        string      `json:"date,omitempty"`
+	Name        string      `json:"name,omitempty"`
+	Qty         int64       `json:"qty"`
+	NetSales    money.Money `json:"net_sales"`
+	CostAmount  money.Money `json:"cost_amount"`
+	GrossMargin money.Money `json:"gross_margin"`
+	MarginRate  money.Rate  `json:"margin_rate"`
+}
+
+// salesMarginColumns sums the units kept, net sales and cost of goods of the
+// matching details, prorated by the quantity that was not refunded
+const salesMarginColumns = `COALESCE(SUM(d.qty - d.refunded_qty), 0) AS qty,
+	COALESCE(SUM(ROUND((d.line_total - d.tax_amount - d.service_charge) * (d.qty - d.refunded_qty) / d.qty, 2)), 0) AS net_sales,
+	COALESCE(SUM(ROUND(d.cost_amount * (d.qty - d.refunded_qty) / d.qty, 2)), 0) AS cost_amount`
+
+// salesDetails selects the transaction details of the sales matching the filter
+// An empty outletCode matches the sales of every outlet
+func (r *TransactionRepository) salesDetails(from, to *time.Time, outletCode string) *gorm.DB {
+	query := r.db.Table("transaction_details AS d").
+		Joins("JOIN transactions AS t ON t.id = d.transaction_id").
+		Where("t.status <> ?", model.TransactionStatusVoided).
+		Where("d.qty > d.refunded_qty")
+
+	if outletCode != "" {
+		query = query.Where("t.outlet_code = ?", outletCode)
+	}
+	if from != nil {
+		query = query.Where("t.created_at >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("t.created_at < ?", *to)
+	}
+	return query
+}
+
+// SalesMargins aggregates the sales of the filter per menu item, category or day
+// Items are ordered by net sales, highest first, and days in date order.
+// Categories are those the menu items are in now
+func (r *TransactionRepository) SalesMargins(filter SalesMarginFilter) ([]SalesMarginRow, error) {
+	query := r.salesDetails(filter.From, filter.To, "")
+
+	switch filter.GroupBy {
+	case MarginByCategory:
+		query = query.
+			Joins("LEFT JOIN menus AS m ON m.id = d.menu_id").
+			Joins("LEFT JOIN categories AS c ON c.id = m.category_id").
+			Select("m.category_id AS category_id, COALESCE(MAX(c.name), 'Uncategorized') AS name, " + salesMarginColumns).
+			Group("m.category_id").
+			Order("net_sales DESC")
+	case MarginByDay:
+		query = query.
+			Select("DATE_FORMAT(t.created_at, '%Y-%m-%d') AS date, " + salesMarginColumns).
+			Group("DATE_FORMAT(t.created_at, '%Y-%m-%d')").
+			Order("date ASC")
+	default:
+		query = query.
+			Select("d.menu_id AS menu_id, MAX(d.menu_name) AS name, " + salesMarginColumns).
+			Group("d.menu_id").
+			Order("net_sales DESC")
+	}
+
+	var rows []SalesMarginRow
+	if err := query.Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for i := range rows {
+		rows[i].fillMargin()
+	}
+	return rows, nil
+}
+
+// SalesMarginTotals aggregates the sales of the filter into a single row
+func (r *TransactionRepository) SalesMarginTotals(filter SalesMarginFilter) (SalesMarginRow, error) {
+	var totals SalesMarginRow
+	err := r.salesDetails(filter.From, filter.To, "").Select(salesMarginColumns).Scan(&totals).Error
+	totals.fillMargin()
+	return totals, err
+}
+
+// fillMargin derives the gross margin and margin rate from net sales and cost
+func (row *SalesMarginRow) fillMargin() {
+	row.GrossMargin = row.NetSales.Sub(row.CostAmount)
+	row.MarginRate = money.RateOf(row.GrossMargin, row.NetSales)
+}
+
+// SalesSummary aggregates the sales of a period
+// GrossSales is the value of the items sold before discounts. NetAmount is the
+// grand total less everything refunded, and AverageBasket is NetAmount per
+// transaction. ItemsSold counts the units that were not refunded
+type SalesSummary struct {
+	TransactionCount int64       `json:"transaction_count"`
+	GrossSales       money.Money `json:"gross_sales"`
+	DiscountAmount   money.Money `json:"discount_amount"`
+	ServiceCharge    money.Money `json:"service_charge"`
+	TaxAmount        money.Money `json:"tax_amount"`
+	TotalAmount      money.Money `json:"total_amount"`
+	RefundedAmount   money.Money `json:"refunded_amount"`
+	NetAmount        money.Money `json:"net_amount"`
+	AverageBasket    money.Money `json:"average_basket"`
+	ItemsSold        int64       `json:"items_sold"`
+}
+
+// TopSellerRow is the units sold and revenue of one menu item
+// Revenue is what customers paid for the units that were not refunded
+type TopSellerRow struct {
+	MenuID  uint        `json:"menu_id"`
+	Name    string      `json:"name"`
+	Qty     int64       `json:"qty"`
+	Revenue money.Money `json:"revenue"`
+}
+
+// HourlySalesRow aggregates the sales rung up within one hour of the day
+type HourlySalesRow struct {
+	Hour             int         `json:"hour"`
+	TransactionCount int64       `json:"transaction_count"`
+	GrossSales       money.Money `json:"gross_sales"`
+	NetAmount        money.Money `json:"net_amount"`
+}
+
+// Top seller orderings
+const (
+	TopSellersByQty     = "qty"
+	TopSellersByRevenue = "revenue"
+)
+
+// salesSummaryColumns sums the amounts of the matching transactions
+const salesSummaryColumns = `COUNT(*) AS transaction_count,
+	COALESCE(SUM(t.subtotal), 0) AS gross_sales,
+	COALESCE(SUM(t.total_amount - t.refunded_amount), 0) AS net_amount`
+
+// salesTransactions selects the transactions of an outlet that are not voided
+// within the period
+func (r *TransactionRepository) salesTransactions(from, to *time.Time, outletCode string) *gorm.DB {
+	query := r.db.Table("transactions AS t").
+		Where("t.status <> ?", model.TransactionStatusVoided).
+		Where("t.outlet_code = ?", outletCode)
+
+	if from != nil {
+		query = query.Where("t.created_at >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("t.created_at < ?", *to)
+	}
+	return query
+}
+
+// SalesSummary aggregates the transactions of an outlet in the period, leaving
+// voided ones out
+func (r *TransactionRepository) SalesSummary(from, to *time.Time, outletCode string) (SalesSummary, error) {
+	var summary SalesSummary
+	err := r.salesTransactions(from, to, outletCode).
+		Select(salesSummaryColumns + `,
+			COALESCE(SUM(t.discount_amount), 0) AS discount_amount,
+			COALESCE(SUM(t.service_charge), 0) AS service_charge,
+			COALESCE(SUM(t.tax_amount), 0) AS tax_amount,
+			COALESCE(SUM(t.total_amount), 0) AS total_amount,
+			COALESCE(SUM(t.refunded_amount), 0) AS refunded_amount`).
+		Scan(&summary).Error
+	if err != nil {
+		return summary, err
+	}
+
+	err = r.salesDetails(from, to, outletCode).
+		Select("COALESCE(SUM(d.qty - d.refunded_qty), 0)").
+		Scan(&summary.ItemsSold).Error
+	if summary.TransactionCount > 0 {
+		summary.AverageBasket = summary.NetAmount.Prorate(1, int(summary.TransactionCount))
+	}
+	return summary, err
+}
+
+// TopSellers returns up to limit menu items sold by an outlet in the period,
+// ordered by units sold or by revenue, highest first
+func (r *TransactionRepository) TopSellers(from, to *time.Time, outletCode, orderBy string, limit int) ([]TopSellerRow, error) {
+	order := "qty DESC, revenue DESC"
+	if orderBy == TopSellersByRevenue {
+		order = "revenue DESC, qty DESC"
+	}
+
+	var rows []TopSellerRow
+	err := r.salesDetails(from, to, outletCode).
+		Select(`d.menu_id AS menu_id, MAX(d.menu_name) AS name,
+			SUM(d.qty - d.refunded_qty) AS qty,
+			SUM(ROUND(d.line_total * (d.qty - d.refunded_qty) / d.qty, 2)) AS revenue`).
+		Group("d.menu_id").
+		Order(order + ", menu_id ASC").
+		Limit(limit).
+		Scan(&rows).Error
+	return rows, err
+}
+
+// HourlySales aggregates the transactions of an outlet in the period per hour of the day
+// Hours without sales are left out; over several days each hour sums every day
+func (r *TransactionRepository) HourlySales(from, to *time.Time, outletCode string) ([]HourlySalesRow, error) {
+	var rows []HourlySalesRow
+	err := r.salesTransactions(from, to, outletCode).
+		Select("HOUR(t.created_at) AS hour, " + salesSummaryColumns).
+		Group("HOUR(t.created_at)").
+		Order("hour ASC").
+		Scan(&rows).Error
+	return rows, err
+}
+
 // BeginTransaction starts a new database transaction
 func (r *TransactionRepository) BeginTransaction() *gorm.DB {
 	return r.db.Begin()
@@ -17,6 +17,7 @@ func NewUserRepository(db *gorm.DB) *UserRepository {
 }
 
 // FindByUsername retrieves a user by username
+// Archived users are not found
 func (r *UserRepository) FindByUsername(username string) (*model.User, error) {
 	var user model.User
 	err := r.db.Where("username = ?", username).First(&user).Error
@@ -26,6 +27,16 @@ func (r *UserRepository) FindByUsername(username string) (*model.User, error) {
 	return &user, nil
 }
 
+// FindByUsernameUnscoped retrieves a user by username including archived users
+func (r *UserRepository) FindByUsernameUnscoped(username string) (*model.User, error) {
+	var user model.User
+	err := r.db.Unscoped().Where("username = ?", username).First(&user).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
 // FindByID retrieves a user by ID
 func (r *UserRepository) FindByID(id uint) (*model.User, error) {
 	var user model.User
@@ -36,6 +47,23 @@ func (r *UserRepository) FindByID(id uint) (*model.User, error) {
 	return &user, nil
 }
 
+// FindByIDUnscoped retrieves a user by ID including archived users
+func (r *UserRepository) FindByIDUnscoped(id uint) (*model.User, error) {
+	var user model.User
+	err := r.db.Unscoped().First(&user, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// ExistsByUsername checks whether any user, including archived ones, uses the username
+func (r *UserRepository) ExistsByUsername(username string) (bool, error) {
+	var count int64
+	err := r.db.Unscoped().Model(&model.User{}).Where("username = ?", username).Count(&count).Error
+	return count > 0, err
+}
+
 // Create creates a new user
 func (r *UserRepository) Create(user *model.User) error {
 	return r.db.Create(user).Error
@@ -46,14 +74,38 @@ func (r *UserRepository) Update(user *model.User) error {
 	return r.db.Save(user).Error
 }
 
-// Delete deletes a user by ID
+// Delete archives a user by ID
 func (r *UserRepository) Delete(id uint) error {
 	return r.db.Delete(&model.User{}, id).Error
 }
 
-// GetAll retrieves all users
-func (r *UserRepository) GetAll() ([]model.User, error) {
+// Restore brings an archived user back
+func (r *UserRepository) Restore(id uint) error {
+	return r.db.Unscoped().Model(&model.User{}).Where("id = ?", id).Update("deleted_at", nil).Error
+}
+
+// GetAll retrieves users ordered by username
+// When archived is set only archived users are returned, otherwise only live ones
+func (r *UserRepository) GetAll(archived bool) ([]model.User, error) {
 	var users []model.User
-	err := r.db.Find(&users).Error
+	query := r.db.Order("username ASC")
+	if archived {
+		query = query.Unscoped().Where("deleted_at IS NOT NULL")
+	}
+	err := query.Find(&users).Error
 	return users, err
 }
+
+// Count returns the total number of users, including archived ones
+func (r *UserRepository) Count() (int64, error) {
+	var count int64
+	err := r.db.Unscoped().Model(&model.User{}).Count(&count).Error
+	return count, err
+}
+
+// CountActiveByRole returns the number of active users with the given role
+func (r *UserRepository) CountActiveByRole(role string) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.User{}).Where("role = ? AND active = ?", role, true).Count(&count).Error
+	return count, err
+}
@@ -3,16 +3,30 @@ package router
 import (
 	"service-cashier/internal/handler"
 	"service-cashier/internal/middleware"
+	"service-cashier/internal/model"
+	"service-cashier/internal/repository"
 
 	"github.com/gin-gonic/gin"
 )
 
 // RouterConfig holds the configuration needed to set up routes
 type RouterConfig struct {
-	AuthHandler        *handler.AuthHandler
-	MenuHandler        *handler.MenuHandler
-	TransactionHandler *handler.TransactionHandler
-	JWTSecret          string
+	AuthHandler          *handler.AuthHandler
+	UserHandler          *handler.UserHandler
+	MenuHandler          *handler.MenuHandler
+	CategoryHandler      *handler.CategoryHandler
+	ModifierHandler      *handler.ModifierHandler
+	InventoryHandler     *handler.InventoryHandler
+	IngredientHandler    *handler.IngredientHandler
+	StockTakeHandler     *handler.StockTakeHandler
+	SupplierHandler      *handler.SupplierHandler
+	PurchaseOrderHandler *handler.PurchaseOrderHandler
+	TransactionHandler   *handler.TransactionHandler
+	RefundHandler        *handler.RefundHandler
+	PromotionHandler     *handler.PromotionHandler
+	ReportHandler        *handler.ReportHandler
+	UserRepo             *repository.UserRepository
+	JWTSecret            string
 }
 
 // SetupRouter configures and returns the Gin router with all routes
@@ -28,14 +42,171 @@ func SetupRouter(config *RouterConfig) *gin.Engine {
 
 		// Protected routes (require JWT authentication)
 		protected := api.Group("")
-		protected.Use(middleware.JWTAuth(config.JWTSecret))
+		protected.Use(middleware.JWTAuth(config.JWTSecret, config.UserRepo))
 		{
 			// Menu routes
 			protected.GET("/menus", config.MenuHandler.GetMenus)
+			protected.GET("/menus/:id", config.MenuHandler.GetMenu)
+
+			// Menu management routes (supervisors and admins)
+			menuAdmin := protected.Group("/menus")
+			menuAdmin.Use(middleware.RequirePermission(model.PermManageMenus))
+			{
+				menuAdmin.POST("", config.MenuHandler.CreateMenu)
+				menuAdmin.PUT("/:id", config.MenuHandler.UpdateMenu)
+				menuAdmin.PATCH("/:id", config.MenuHandler.PatchMenu)
+				menuAdmin.DELETE("/:id", config.MenuHandler.DeleteMenu)
+				menuAdmin.POST("/:id/restore", config.MenuHandler.RestoreMenu)
+				menuAdmin.GET("/:id/stock-movements", config.InventoryHandler.GetStockMovements)
+				menuAdmin.POST("/:id/stock/receive", config.InventoryHandler.ReceiveStock)
+				menuAdmin.POST("/:id/stock/adjust", config.InventoryHandler.AdjustStock)
+				menuAdmin.POST("/:id/stock/waste", config.InventoryHandler.WasteStock)
+				menuAdmin.PUT("/:id/modifier-groups", config.MenuHandler.SetModifierGroups)
+				menuAdmin.PUT("/:id/recipe", config.IngredientHandler.SetMenuRecipe)
+			}
+
+			// Sold-out toggles (every role, so floor staff can take an item off sale)
+			protected.POST("/menus/:id/disable", middleware.RequirePermission(model.PermToggleMenus), config.MenuHandler.DisableMenu)
+			protected.POST("/menus/:id/enable", middleware.RequirePermission(model.PermToggleMenus), config.MenuHandler.EnableMenu)
+
+			// Category routes
+			protected.GET("/categories", config.CategoryHandler.GetCategories)
+			protected.GET("/categories/:id", config.CategoryHandler.GetCategory)
+
+			// Category management routes (supervisors and admins)
+			categoryAdmin := protected.Group("/categories")
+			categoryAdmin.Use(middleware.RequirePermission(model.PermManageMenus))
+			{
+				categoryAdmin.POST("", config.CategoryHandler.CreateCategory)
+				categoryAdmin.PUT("/:id", config.CategoryHandler.UpdateCategory)
+				categoryAdmin.DELETE("/:id", config.CategoryHandler.DeleteCategory)
+			}
+
+			// Modifier group routes
+			protected.GET("/modifier-groups", config.ModifierHandler.GetModifierGroups)
+			protected.GET("/modifier-groups/:id", config.ModifierHandler.GetModifierGroup)
+
+			// Modifier group management routes (supervisors and admins)
+			modifierAdmin := protected.Group("/modifier-groups")
+			modifierAdmin.Use(middleware.RequirePermission(model.PermManageMenus))
+			{
+				modifierAdmin.POST("", config.ModifierHandler.CreateModifierGroup)
+				modifierAdmin.PUT("/:id", config.ModifierHandler.UpdateModifierGroup)
+				modifierAdmin.DELETE("/:id", config.ModifierHandler.DeleteModifierGroup)
+			}
+
+			// Modifier option recipes (supervisors and admins)
+			optionAdmin := protected.Group("/modifier-options")
+			optionAdmin.Use(middleware.RequirePermission(model.PermManageMenus))
+			{
+				optionAdmin.GET("/:id/recipe", config.IngredientHandler.GetOptionRecipe)
+				optionAdmin.PUT("/:id/recipe", config.IngredientHandler.SetOptionRecipe)
+			}
+
+			// Ingredient routes (supervisors and admins)
+			ingredients := protected.Group("/ingredients")
+			ingredients.Use(middleware.RequirePermission(model.PermManageMenus))
+			{
+				ingredients.GET("", config.IngredientHandler.GetIngredients)
+				ingredients.GET("/:id", config.IngredientHandler.GetIngredient)
+				ingredients.POST("", config.IngredientHandler.CreateIngredient)
+				ingredients.PUT("/:id", config.IngredientHandler.UpdateIngredient)
+				ingredients.DELETE("/:id", config.IngredientHandler.DeleteIngredient)
+				ingredients.GET("/:id/movements", config.IngredientHandler.GetIngredientMovements)
+				ingredients.POST("/:id/stock/receive", config.IngredientHandler.ReceiveIngredient)
+				ingredients.POST("/:id/stock/adjust", config.IngredientHandler.AdjustIngredient)
+				ingredients.POST("/:id/stock/waste", config.IngredientHandler.WasteIngredient)
+			}
+
+			// Inventory routes (supervisors and admins)
+			inventory := protected.Group("/inventory")
+			inventory.Use(middleware.RequirePermission(model.PermManageMenus))
+			{
+				inventory.GET("/low-stock", config.InventoryHandler.GetLowStock)
+			}
+
+			// Stock take routes: all staff count, supervisors and admins start and close sessions
+			stockTakes := protected.Group("/stock-takes")
+			stockTakes.Use(middleware.RequirePermission(model.PermCountStock))
+			{
+				stockTakes.GET("", config.StockTakeHandler.GetStockTakes)
+				stockTakes.GET("/:id", config.StockTakeHandler.GetStockTake)
+				stockTakes.GET("/:id/report.csv", config.StockTakeHandler.GetVarianceReport)
+				stockTakes.POST("/:id/counts", config.StockTakeHandler.SubmitCounts)
+				stockTakes.POST("", middleware.RequirePermission(model.PermManageMenus), config.StockTakeHandler.CreateStockTake)
+				stockTakes.POST("/:id/approve", middleware.RequirePermission(model.PermManageMenus), config.StockTakeHandler.ApproveStockTake)
+				stockTakes.POST("/:id/cancel", middleware.RequirePermission(model.PermManageMenus), config.StockTakeHandler.CancelStockTake)
+			}
+
+			// Supplier routes (supervisors and admins)
+			suppliers := protected.Group("/suppliers")
+			suppliers.Use(middleware.RequirePermission(model.PermManagePurchasing))
+			{
+				suppliers.GET("", config.SupplierHandler.GetSuppliers)
+				suppliers.GET("/:id", config.SupplierHandler.GetSupplier)
+				suppliers.POST("", config.SupplierHandler.CreateSupplier)
+				suppliers.PUT("/:id", config.SupplierHandler.UpdateSupplier)
+				suppliers.DELETE("/:id", config.SupplierHandler.DeleteSupplier)
+			}
+
+			// Purchase order routes (supervisors and admins)
+			purchaseOrders := protected.Group("/purchase-orders")
+			purchaseOrders.Use(middleware.RequirePermission(model.PermManagePurchasing))
+			{
+				purchaseOrders.GET("", config.PurchaseOrderHandler.GetPurchaseOrders)
+				purchaseOrders.GET("/:id", config.PurchaseOrderHandler.GetPurchaseOrder)
+				purchaseOrders.POST("", config.PurchaseOrderHandler.CreatePurchaseOrder)
+				purchaseOrders.PUT("/:id", config.PurchaseOrderHandler.UpdatePurchaseOrder)
+				purchaseOrders.DELETE("/:id", config.PurchaseOrderHandler.DeletePurchaseOrder)
+				purchaseOrders.POST("/:id/send", config.PurchaseOrderHandler.SendPurchaseOrder)
+				purchaseOrders.POST("/:id/receive", config.PurchaseOrderHandler.ReceivePurchaseOrder)
+				purchaseOrders.POST("/:id/close", config.PurchaseOrderHandler.ClosePurchaseOrder)
+			}
+
+			// Promotion management routes (supervisors and admins)
+			promotions := protected.Group("/promotions")
+			promotions.Use(middleware.RequirePermission(model.PermManagePromotions))
+			{
+				promotions.GET("", config.PromotionHandler.GetPromotions)
+				promotions.GET("/:id", config.PromotionHandler.GetPromotion)
+				promotions.POST("", config.PromotionHandler.CreatePromotion)
+				promotions.PUT("/:id", config.PromotionHandler.UpdatePromotion)
+				promotions.DELETE("/:id", config.PromotionHandler.DeletePromotion)
+			}
+
+			// Report routes (supervisors and admins)
+			reports := protected.Group("/reports")
+			reports.Use(middleware.RequirePermission(model.PermViewReports))
+			{
+				reports.GET("/margins", config.ReportHandler.GetMarginReport)
+				reports.GET("/sales-summary", config.ReportHandler.GetSalesSummary)
+			}
+
+			// User management routes (admins only)
+			users := protected.Group("/users")
+			users.Use(middleware.RequirePermission(model.PermManageUsers))
+			{
+				users.GET("", config.UserHandler.GetUsers)
+				users.GET("/:id", config.UserHandler.GetUser)
+				users.POST("", config.UserHandler.CreateUser)
+				users.PUT("/:id/role", config.UserHandler.UpdateRole)
+				users.POST("/:id/disable", config.UserHandler.DisableUser)
+				users.POST("/:id/enable", config.UserHandler.EnableUser)
+				users.POST("/:id/reset-password", config.UserHandler.ResetPassword)
+				users.DELETE("/:id", config.UserHandler.DeleteUser)
+				users.POST("/:id/restore", config.UserHandler.RestoreUser)
+			}
 
 			// Transaction routes
-			protected.POST("/checkout", config.TransactionHandler.Checkout)
-			protected.GET("/transactions", config.TransactionHandler.GetTransactions)
+			protected.POST("/checkout", middleware.RequirePermission(model.PermCheckout), config.TransactionHandler.Checkout)
+			protected.GET("/transactions", middleware.RequirePermission(model.PermViewOwnTransactions), config.TransactionHandler.GetTransactions)
+			protected.GET("/transactions/all", middleware.RequirePermission(model.PermViewAllTransactions), config.TransactionHandler.GetAllTransactions)
+			protected.GET("/transactions/:id", middleware.RequirePermission(model.PermViewOwnTransactions), config.TransactionHandler.GetTransaction)
+
+			// Void and refund routes (supervisors and admins)
+			protected.POST("/transactions/:id/void", middleware.RequirePermission(model.PermVoidTransactions), config.RefundHandler.VoidTransaction)
+			protected.POST("/transactions/:id/refunds", middleware.RequirePermission(model.PermRefundTransactions), config.RefundHandler.RefundTransaction)
+			protected.GET("/transactions/:id/refunds", middleware.RequirePermission(model.PermViewAllTransactions), config.RefundHandler.GetRefunds)
 		}
 	}
 
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"errors"
+	"service-cashier/internal/model"
+	"service-cashier/internal/repository"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrCategoryNotFound is returned when the requested category does not exist
+	ErrCategoryNotFound = errors.New("category not found")
+	// ErrCategoryNameExists is returned when another category already uses the name
+	ErrCategoryNameExists = errors.New("category with this name already exists")
+	// ErrCategoryInUse is returned when deleting a category that still has menu items
+	ErrCategoryInUse = errors.New("category still has menu items; move them or deactivate the category instead")
+	// ErrCategoryNameRequired is returned when the category name is blank after trimming
+	ErrCategoryNameRequired = errors.New("category name cannot be empty")
+)
+
+// CategoryService handles menu category business logic
+type CategoryService struct {
+	categoryRepo *repository.CategoryRepository
+}
+
+// NewCategoryService creates a new CategoryService instance
+func NewCategoryService(categoryRepo *repository.CategoryRepository) *CategoryService {
+	return &CategoryService{categoryRepo: categoryRepo}
+}
+
+// CategoryRequest represents the create and update category payload
+// Active defaults to true when omitted
+type CategoryRequest struct {
+	Name         string `json:"name" binding:"required,max=50"`
+	DisplayOrder int    `json:"display_order"`
+	Active       *bool  `json:"active"`
+}
+
+// GetAllCategories retrieves categories ordered by display order
+// Inactive categories are only included when includeInactive is set
+func (s *CategoryService) GetAllCategories(includeInactive bool) ([]model.Category, error) {
+	return s.categoryRepo.GetAll(!includeInactive)
+}
+
+// GetCategoryByID retrieves a category by ID
+func (s *CategoryService) GetCategoryByID(id uint) (*model.Category, error) {
+	category, err := s.categoryRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCategoryNotFound
+		}
+		return nil, err
+	}
+	return category, nil
+}
+
+// CreateCategory creates a new category
+func (s *CategoryService) CreateCategory(req *CategoryRequest) (*model.Category, error) {
+	category := &model.Category{Active: true}
+	if err := applyCategoryRequest(category, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.categoryRepo.Create(category); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrCategoryNameExists
+		}
+		return nil, err
+	}
+	return category, nil
+}
+
+// UpdateCategory replaces the editable fields of an existing category
+func (s *CategoryService) UpdateCategory(id uint, req *CategoryRequest) (*model.Category, error) {
+	category, err := s.GetCategoryByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyCategoryRequest(category, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.categoryRepo.Update(category); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrCategoryNameExists
+		}
+		return nil, err
+	}
+	return category, nil
+}
+
+// DeleteCategory deletes a category that has no menu items
+func (s *CategoryService) DeleteCategory(id uint) error {
+	if _, err := s.GetCategoryByID(id); err != nil {
+		return err
+	}
+
+	hasMenus, err := s.categoryRepo.HasMenus(id)
+	if err != nil {
+		return err
+	}
+	if hasMenus {
+		return ErrCategoryInUse
+	}
+
+	return s.categoryRepo.Delete(id)
+}
+
+// applyCategoryRequest copies the request fields onto the category
+func applyCategoryRequest(category *model.Category, req *CategoryRequest) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return ErrCategoryNameRequired
+	}
+
+	category.Name = name
+	category.DisplayOrder = req.DisplayOrder
+	if req.Active != nil {
+		category.Active = *req.Active
+	}
+	return nil
+}
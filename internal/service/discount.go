@@ -0,0 +1,273 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"service-cashier/internal/model"
+	"service-cashier/pkg/money"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrPromotionNotFound is returned when a promo code does not exist
+	ErrPromotionNotFound = errors.New("promo code not found")
+	// ErrPromotionNotRedeemable is returned when a promo code is inactive, expired or used up
+	ErrPromotionNotRedeemable = errors.New("promo code is not valid at this time")
+	// ErrPromotionNotApplicable is returned when the order does not meet the promotion conditions
+	ErrPromotionNotApplicable = errors.New("promo code does not apply to this order")
+	// ErrDiscountExceedsLimit is returned when a manual discount is above the cashier's role limit
+	ErrDiscountExceedsLimit = errors.New("discount exceeds the limit allowed for your role")
+)
+
+// Manual discount types
+const (
+	DiscountTypePercentage = "percentage"
+	DiscountTypeFixed      = "fixed"
+)
+
+// ManualDiscount represents a discount keyed in by the cashier
+// For percentage discounts Value is a percentage such as 10 or 12.5
+type ManualDiscount struct {
+	Type   string      `json:"type" binding:"required,oneof=percentage fixed"`
+	Value  money.Money `json:"value" binding:"required,gt=0"`
+	Reason string      `json:"reason" binding:"required,max=255"`
+}
+
+// DiscountPolicy limits manual discounts per role
+// Each limit is the largest share of a sale's gross subtotal, as a percentage,
+// that a role may give in manual discounts altogether
+type DiscountPolicy struct {
+	MaxManualPercent map[string]money.Rate
+}
+
+// NewDiscountPolicy parses the manual discount limits for each role from configuration
+func NewDiscountPolicy(cashier, supervisor, admin string) (DiscountPolicy, error) {
+	limits := map[string]string{
+		model.RoleCashier:    cashier,
+		model.RoleSupervisor: supervisor,
+		model.RoleAdmin:      admin,
+	}
+
+	policy := DiscountPolicy{MaxManualPercent: make(map[string]money.Rate, len(limits))}
+	for role, value := range limits {
+		rate, err := money.ParseRate(value)
+		if err != nil {
+			return DiscountPolicy{}, fmt.Errorf("manual discount limit for %s: %w", role, err)
+		}
+		policy.MaxManualPercent[role] = rate
+	}
+	return policy, nil
+}
+
+// discountLine is the working state of a checkout line while discounts are applied
+type discountLine struct {
+	MenuID    uint
+	UnitPrice money.Money
+	Qty       int
+	Gross     money.Money
+	Discount  money.Money
+}
+
+// net returns the line amount after discounts applied so far
+func (l *discountLine) net() money.Money {
+	return l.Gross.Sub(l.Discount)
+}
+
+// add applies up to amount of discount to the line without taking it below zero
+// and returns the amount actually applied
+func (l *discountLine) add(amount money.Money) money.Money {
+	if amount > l.net() {
+		amount = l.net()
+	}
+	l.Discount = l.Discount.Add(amount)
+	return amount
+}
+
+// appliedDiscount is a discount applied during checkout
+// LineIndex is -1 for order-level discounts
+type appliedDiscount struct {
+	LineIndex   int
+	PromotionID *uint
+	Source      string
+	Code        string
+	Description string
+	Amount      money.Money
+}
+
+// netTotal returns the sum of the line amounts after discounts
+func netTotal(lines []*discountLine) money.Money {
+	var total money.Money
+	for _, line := range lines {
+		total = total.Add(line.net())
+	}
+	return total
+}
+
+// applyPromotion applies a promotion to the checkout lines
+func applyPromotion(lines []*discountLine, promotion *model.Promotion, now time.Time) ([]appliedDiscount, error) {
+	if !promotion.IsRedeemableAt(now) {
+		return nil, ErrPromotionNotRedeemable
+	}
+	if netTotal(lines) < promotion.MinSubtotal {
+		return nil, fmt.Errorf("%w: minimum order is %s", ErrPromotionNotApplicable, promotion.MinSubtotal)
+	}
+
+	var applied []appliedDiscount
+	record := func(index int, amount money.Money) {
+		if amount.IsZero() {
+			return
+		}
+		id := promotion.ID
+		applied = append(applied, appliedDiscount{
+			LineIndex:   index,
+			PromotionID: &id,
+			Source:      model.DiscountSourcePromotion,
+			Code:        promotion.Code,
+			Description: promotion.Name,
+			Amount:      amount,
+		})
+	}
+
+	switch {
+	case promotion.Type == model.PromotionTypeBuyXGetY:
+		discounts := buyXGetYDiscounts(lines, promotion)
+		for index := range lines {
+			if amount, ok := discounts[index]; ok {
+				record(index, lines[index].add(amount))
+			}
+		}
+
+	case promotion.Scope == model.PromotionScopeItem:
+		remaining := promotion.MaxDiscount
+		for index, line := range lines {
+			if promotion.MenuID == nil || line.MenuID != *promotion.MenuID {
+				continue
+			}
+
+			amount := promotion.Amount.Mul(line.Qty)
+			if promotion.Type == model.PromotionTypePercentage {
+				amount = line.net().MulRate(promotion.Percentage)
+			}
+			if !promotion.MaxDiscount.IsZero() {
+				if amount > remaining {
+					amount = remaining
+				}
+				remaining = remaining.Sub(amount)
+			}
+			record(index, line.add(amount))
+		}
+
+	default:
+		base := netTotal(lines)
+		amount := promotion.Amount
+		if promotion.Type == model.PromotionTypePercentage {
+			amount = base.MulRate(promotion.Percentage)
+		}
+		if !promotion.MaxDiscount.IsZero() && amount > promotion.MaxDiscount {
+			amount = promotion.MaxDiscount
+		}
+		record(-1, allocateOrderDiscount(lines, amount))
+	}
+
+	if len(applied) == 0 {
+		return nil, ErrPromotionNotApplicable
+	}
+	return applied, nil
+}
+
+// buyXGetYDiscounts returns the discount per line index for a buy-X-get-Y promotion
+// Free units are taken from the cheapest matching lines first
+func buyXGetYDiscounts(lines []*discountLine, promotion *model.Promotion) map[int]money.Money {
+	discounts := make(map[int]money.Money)
+	if promotion.MenuID == nil || promotion.BuyQty <= 0 || promotion.GetQty <= 0 {
+		return discounts
+	}
+
+	var matching []int
+	totalQty := 0
+	for index, line := range lines {
+		if line.MenuID == *promotion.MenuID {
+			matching = append(matching, index)
+			totalQty += line.Qty
+		}
+	}
+
+	freeQty := totalQty / (promotion.BuyQty + promotion.GetQty) * promotion.GetQty
+	sort.SliceStable(matching, func(i, j int) bool {
+		return lines[matching[i]].UnitPrice < lines[matching[j]].UnitPrice
+	})
+
+	for _, index := range matching {
+		if freeQty == 0 {
+			break
+		}
+		qty := lines[index].Qty
+		if qty > freeQty {
+			qty = freeQty
+		}
+		discounts[index] = lines[index].UnitPrice.Mul(qty)
+		freeQty -= qty
+	}
+	return discounts
+}
+
+// applyManualDiscount applies a cashier-entered discount to target, which is either
+// the single line at lineIndex or, when lineIndex is -1, every line of the order
+// Percentages are of the target's net amount. The role limit is checked across
+// all manual discounts of the sale by checkManualDiscountLimit
+func applyManualDiscount(target []*discountLine, lineIndex int, discount *ManualDiscount) appliedDiscount {
+	amount := discount.Value
+	if discount.Type == DiscountTypePercentage {
+		amount = netTotal(target).MulRate(money.Rate(discount.Value))
+	}
+
+	applied := appliedDiscount{
+		LineIndex:   lineIndex,
+		Source:      model.DiscountSourceManual,
+		Description: strings.TrimSpace(discount.Reason),
+	}
+	if lineIndex >= 0 {
+		applied.Amount = target[0].add(amount)
+	} else {
+		applied.Amount = allocateOrderDiscount(target, amount)
+	}
+	return applied
+}
+
+// checkManualDiscountLimit checks that the manual discounts of a sale add up to
+// no more than the role limit, as a percentage of the gross subtotal
+// Item and order discounts are capped together so that stacking them cannot
+// exceed the limit
+func checkManualDiscountLimit(applied []appliedDiscount, grossSubtotal money.Money, maxPercent money.Rate) error {
+	var total money.Money
+	for _, discount := range applied {
+		if discount.Source == model.DiscountSourceManual {
+			total = total.Add(discount.Amount)
+		}
+	}
+	if total > grossSubtotal.MulRate(maxPercent) {
+		return fmt.Errorf("%w (maximum %s%% of the subtotal)", ErrDiscountExceedsLimit, maxPercent)
+	}
+	return nil
+}
+
+// allocateOrderDiscount spreads an order-level discount across the lines in
+// proportion to their net amounts and returns the amount actually applied
+func allocateOrderDiscount(lines []*discountLine, amount money.Money) money.Money {
+	base := netTotal(lines)
+	if amount > base {
+		amount = base
+	}
+
+	weights := make([]money.Money, len(lines))
+	for i, line := range lines {
+		weights[i] = line.net()
+	}
+
+	for i, share := range money.Allocate(amount, weights) {
+		lines[i].add(share)
+	}
+	return amount
+}
@@ -0,0 +1,373 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"service-cashier/internal/model"
+	"service-cashier/pkg/money"
+	"testing"
+	"time"
+)
+
+// newDiscountLines builds checkout lines from (menu ID, unit price, quantity) triples
+func newDiscountLines(specs ...[3]int64) []*discountLine {
+	lines := make([]*discountLine, len(specs))
+	for i, spec := range specs {
+		price := money.FromMinor(spec[1])
+		lines[i] = &discountLine{
+			MenuID:    uint(spec[0]),
+			UnitPrice: price,
+			Qty:       int(spec[2]),
+			Gross:     price.Mul(int(spec[2])),
+		}
+	}
+	return lines
+}
+
+// lineDiscounts returns the discount applied so far to each line
+func lineDiscounts(lines []*discountLine) []money.Money {
+	discounts := make([]money.Money, len(lines))
+	for i, line := range lines {
+		discounts[i] = line.Discount
+	}
+	return discounts
+}
+
+func TestAllocateOrderDiscount(t *testing.T) {
+	tests := []struct {
+		name          string
+		lines         []*discountLine
+		existing      []money.Money
+		amount        money.Money
+		wantApplied   money.Money
+		wantDiscounts []money.Money
+	}{
+		{
+			name:          "proportional to net amounts",
+			lines:         newDiscountLines([3]int64{1, 1000, 1}, [3]int64{2, 1000, 2}),
+			amount:        300,
+			wantApplied:   300,
+			wantDiscounts: []money.Money{100, 200},
+		},
+		{
+			name:          "leftover cent goes to the largest remainder",
+			lines:         newDiscountLines([3]int64{1, 100, 1}, [3]int64{2, 100, 1}, [3]int64{3, 100, 1}),
+			amount:        100,
+			wantApplied:   100,
+			wantDiscounts: []money.Money{34, 33, 33},
+		},
+		{
+			name:          "capped at the order net amount",
+			lines:         newDiscountLines([3]int64{1, 1000, 1}),
+			amount:        1500,
+			wantApplied:   1000,
+			wantDiscounts: []money.Money{1000},
+		},
+		{
+			name:          "weighted by amounts after earlier discounts",
+			lines:         newDiscountLines([3]int64{1, 1000, 1}, [3]int64{2, 500, 1}),
+			existing:      []money.Money{500, 0},
+			amount:        100,
+			wantApplied:   100,
+			wantDiscounts: []money.Money{550, 50},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i, discount := range tt.existing {
+				tt.lines[i].Discount = discount
+			}
+
+			applied := allocateOrderDiscount(tt.lines, tt.amount)
+			if applied != tt.wantApplied {
+				t.Errorf("applied = %s, want %s", applied, tt.wantApplied)
+			}
+			if got := lineDiscounts(tt.lines); !reflect.DeepEqual(got, tt.wantDiscounts) {
+				t.Errorf("line discounts = %v, want %v", got, tt.wantDiscounts)
+			}
+		})
+	}
+}
+
+func TestBuyXGetYDiscounts(t *testing.T) {
+	menuID := uint(1)
+
+	tests := []struct {
+		name      string
+		lines     []*discountLine
+		promotion *model.Promotion
+		want      map[int]money.Money
+	}{
+		{
+			name:      "buy two get one",
+			lines:     newDiscountLines([3]int64{1, 1000, 3}),
+			promotion: &model.Promotion{MenuID: &menuID, BuyQty: 2, GetQty: 1},
+			want:      map[int]money.Money{0: 1000},
+		},
+		{
+			name:      "cheapest matching line is free first",
+			lines:     newDiscountLines([3]int64{1, 1500, 2}, [3]int64{1, 1000, 1}, [3]int64{2, 500, 5}),
+			promotion: &model.Promotion{MenuID: &menuID, BuyQty: 1, GetQty: 1},
+			want:      map[int]money.Money{1: 1000},
+		},
+		{
+			name:      "free units span lines",
+			lines:     newDiscountLines([3]int64{1, 1200, 3}, [3]int64{1, 800, 1}),
+			promotion: &model.Promotion{MenuID: &menuID, BuyQty: 1, GetQty: 1},
+			want:      map[int]money.Money{0: 1200, 1: 800},
+		},
+		{
+			name:      "not enough units",
+			lines:     newDiscountLines([3]int64{1, 1000, 2}),
+			promotion: &model.Promotion{MenuID: &menuID, BuyQty: 2, GetQty: 1},
+			want:      map[int]money.Money{},
+		},
+		{
+			name:      "no menu",
+			lines:     newDiscountLines([3]int64{1, 1000, 4}),
+			promotion: &model.Promotion{BuyQty: 1, GetQty: 1},
+			want:      map[int]money.Money{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := buyXGetYDiscounts(tt.lines, tt.promotion); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("buyXGetYDiscounts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyPromotion(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	expired := now.Add(-time.Hour)
+	menuID := uint(2)
+
+	type wantDiscount struct {
+		LineIndex int
+		Amount    money.Money
+	}
+
+	tests := []struct {
+		name          string
+		lines         []*discountLine
+		promotion     model.Promotion
+		inactive      bool
+		wantErr       error
+		want          []wantDiscount
+		wantDiscounts []money.Money
+	}{
+		{
+			name:          "order percentage",
+			lines:         newDiscountLines([3]int64{1, 1000, 1}, [3]int64{2, 2000, 1}),
+			promotion:     model.Promotion{Type: model.PromotionTypePercentage, Scope: model.PromotionScopeOrder, Percentage: 1000},
+			want:          []wantDiscount{{LineIndex: -1, Amount: 300}},
+			wantDiscounts: []money.Money{100, 200},
+		},
+		{
+			name:          "order percentage capped at the maximum discount",
+			lines:         newDiscountLines([3]int64{1, 1000, 1}, [3]int64{2, 2000, 1}),
+			promotion:     model.Promotion{Type: model.PromotionTypePercentage, Scope: model.PromotionScopeOrder, Percentage: 1000, MaxDiscount: 150},
+			want:          []wantDiscount{{LineIndex: -1, Amount: 150}},
+			wantDiscounts: []money.Money{50, 100},
+		},
+		{
+			name:          "order fixed amount capped at the order total",
+			lines:         newDiscountLines([3]int64{1, 1000, 1}, [3]int64{2, 2000, 1}),
+			promotion:     model.Promotion{Type: model.PromotionTypeFixed, Scope: model.PromotionScopeOrder, Amount: 5000},
+			want:          []wantDiscount{{LineIndex: -1, Amount: 3000}},
+			wantDiscounts: []money.Money{1000, 2000},
+		},
+		{
+			name:          "item fixed amount per unit",
+			lines:         newDiscountLines([3]int64{1, 1000, 1}, [3]int64{2, 500, 3}),
+			promotion:     model.Promotion{Type: model.PromotionTypeFixed, Scope: model.PromotionScopeItem, Amount: 200, MenuID: &menuID},
+			want:          []wantDiscount{{LineIndex: 1, Amount: 600}},
+			wantDiscounts: []money.Money{0, 600},
+		},
+		{
+			name:          "item percentage shares the maximum discount across lines",
+			lines:         newDiscountLines([3]int64{2, 1000, 1}, [3]int64{2, 1000, 1}),
+			promotion:     model.Promotion{Type: model.PromotionTypePercentage, Scope: model.PromotionScopeItem, Percentage: 5000, MaxDiscount: 700, MenuID: &menuID},
+			want:          []wantDiscount{{LineIndex: 0, Amount: 500}, {LineIndex: 1, Amount: 200}},
+			wantDiscounts: []money.Money{500, 200},
+		},
+		{
+			name:          "buy one get one",
+			lines:         newDiscountLines([3]int64{2, 800, 2}),
+			promotion:     model.Promotion{Type: model.PromotionTypeBuyXGetY, MenuID: &menuID, BuyQty: 1, GetQty: 1},
+			want:          []wantDiscount{{LineIndex: 0, Amount: 800}},
+			wantDiscounts: []money.Money{800},
+		},
+		{
+			name:      "inactive",
+			lines:     newDiscountLines([3]int64{1, 1000, 1}),
+			promotion: model.Promotion{Type: model.PromotionTypeFixed, Scope: model.PromotionScopeOrder, Amount: 100},
+			inactive:  true,
+			wantErr:   ErrPromotionNotRedeemable,
+		},
+		{
+			name:      "expired",
+			lines:     newDiscountLines([3]int64{1, 1000, 1}),
+			promotion: model.Promotion{Type: model.PromotionTypeFixed, Scope: model.PromotionScopeOrder, Amount: 100, EndsAt: &expired},
+			wantErr:   ErrPromotionNotRedeemable,
+		},
+		{
+			name:      "below the minimum order",
+			lines:     newDiscountLines([3]int64{1, 1000, 1}),
+			promotion: model.Promotion{Type: model.PromotionTypeFixed, Scope: model.PromotionScopeOrder, Amount: 100, MinSubtotal: 1001},
+			wantErr:   ErrPromotionNotApplicable,
+		},
+		{
+			name:      "item not in the order",
+			lines:     newDiscountLines([3]int64{1, 1000, 1}),
+			promotion: model.Promotion{Type: model.PromotionTypeFixed, Scope: model.PromotionScopeItem, Amount: 100, MenuID: &menuID},
+			wantErr:   ErrPromotionNotApplicable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			promotion := tt.promotion
+			promotion.ID = 7
+			promotion.Code = "PROMO"
+			promotion.Active = !tt.inactive
+
+			applied, err := applyPromotion(tt.lines, &promotion, now)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			got := make([]wantDiscount, len(applied))
+			for i, discount := range applied {
+				got[i] = wantDiscount{LineIndex: discount.LineIndex, Amount: discount.Amount}
+				if discount.Source != model.DiscountSourcePromotion || discount.Code != "PROMO" ||
+					discount.PromotionID == nil || *discount.PromotionID != 7 {
+					t.Errorf("discount %d = %+v, want promotion 7 (PROMO)", i, discount)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("applied = %+v, want %+v", got, tt.want)
+			}
+			if discounts := lineDiscounts(tt.lines); !reflect.DeepEqual(discounts, tt.wantDiscounts) {
+				t.Errorf("line discounts = %v, want %v", discounts, tt.wantDiscounts)
+			}
+		})
+	}
+}
+
+func TestApplyManualDiscount(t *testing.T) {
+	tests := []struct {
+		name          string
+		lines         []*discountLine
+		lineIndex     int
+		discount      ManualDiscount
+		wantAmount    money.Money
+		wantDiscounts []money.Money
+	}{
+		{
+			name:          "order percentage",
+			lines:         newDiscountLines([3]int64{1, 1000, 1}, [3]int64{2, 3000, 1}),
+			lineIndex:     -1,
+			discount:      ManualDiscount{Type: DiscountTypePercentage, Value: money.MustParse("10"), Reason: " regular "},
+			wantAmount:    400,
+			wantDiscounts: []money.Money{100, 300},
+		},
+		{
+			name:          "order fixed amount",
+			lines:         newDiscountLines([3]int64{1, 1000, 1}, [3]int64{2, 3000, 1}),
+			lineIndex:     -1,
+			discount:      ManualDiscount{Type: DiscountTypeFixed, Value: 1000, Reason: "regular"},
+			wantAmount:    1000,
+			wantDiscounts: []money.Money{250, 750},
+		},
+		{
+			name:          "line percentage is rounded",
+			lines:         newDiscountLines([3]int64{1, 333, 1}),
+			lineIndex:     0,
+			discount:      ManualDiscount{Type: DiscountTypePercentage, Value: money.MustParse("12.5"), Reason: "damaged"},
+			wantAmount:    42,
+			wantDiscounts: []money.Money{42},
+		},
+		{
+			name:          "line fixed amount capped at the line net",
+			lines:         newDiscountLines([3]int64{1, 500, 1}),
+			lineIndex:     0,
+			discount:      ManualDiscount{Type: DiscountTypeFixed, Value: 800, Reason: "damaged"},
+			wantAmount:    500,
+			wantDiscounts: []money.Money{500},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := tt.lines
+			if tt.lineIndex >= 0 {
+				target = tt.lines[tt.lineIndex : tt.lineIndex+1]
+			}
+
+			applied := applyManualDiscount(target, tt.lineIndex, &tt.discount)
+			if applied.Amount != tt.wantAmount {
+				t.Errorf("amount = %s, want %s", applied.Amount, tt.wantAmount)
+			}
+			if applied.LineIndex != tt.lineIndex || applied.Source != model.DiscountSourceManual || applied.PromotionID != nil {
+				t.Errorf("discount = %+v, want a manual discount on line %d", applied, tt.lineIndex)
+			}
+			if applied.Description == "" || applied.Description[0] == ' ' {
+				t.Errorf("description = %q, want the trimmed reason", applied.Description)
+			}
+			if got := lineDiscounts(tt.lines); !reflect.DeepEqual(got, tt.wantDiscounts) {
+				t.Errorf("line discounts = %v, want %v", got, tt.wantDiscounts)
+			}
+		})
+	}
+}
+
+func TestCheckManualDiscountLimit(t *testing.T) {
+	promotionID := uint(1)
+
+	manual := func(amount money.Money) appliedDiscount {
+		return appliedDiscount{LineIndex: -1, Source: model.DiscountSourceManual, Amount: amount}
+	}
+	promotion := func(amount money.Money) appliedDiscount {
+		return appliedDiscount{LineIndex: -1, PromotionID: &promotionID, Source: model.DiscountSourcePromotion, Amount: amount}
+	}
+
+	tests := []struct {
+		name       string
+		applied    []appliedDiscount
+		gross      money.Money
+		maxPercent money.Rate
+		wantErr    bool
+	}{
+		{name: "no discounts", gross: 10000, maxPercent: 1000},
+		{name: "stacked discounts at the limit", applied: []appliedDiscount{manual(600), manual(400)}, gross: 10000, maxPercent: 1000},
+		{name: "stacked discounts over the limit", applied: []appliedDiscount{manual(600), manual(401)}, gross: 10000, maxPercent: 1000, wantErr: true},
+		{name: "promotions do not count", applied: []appliedDiscount{promotion(5000), manual(1000)}, gross: 10000, maxPercent: 1000},
+		{name: "limit is rounded", applied: []appliedDiscount{manual(34)}, gross: 333, maxPercent: 1000, wantErr: true},
+		{name: "zero limit", applied: []appliedDiscount{manual(1)}, gross: 10000, maxPercent: 0, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkManualDiscountLimit(tt.applied, tt.gross, tt.maxPercent)
+			if tt.wantErr {
+				if !errors.Is(err, ErrDiscountExceedsLimit) {
+					t.Fatalf("error = %v, want ErrDiscountExceedsLimit", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
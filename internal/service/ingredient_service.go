@@ -0,0 +1,462 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"service-cashier/internal/model"
+	"service-cashier/internal/repository"
+	"service-cashier/pkg/money"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrIngredientNotFound is returned when the requested ingredient does not exist
+	ErrIngredientNotFound = errors.New("ingredient not found")
+	// ErrIngredientNameExists is returned when another ingredient already uses the name
+	ErrIngredientNameExists = errors.New("ingredient with this name already exists")
+	// ErrIngredientNameRequired is returned when the ingredient name is blank after trimming
+	ErrIngredientNameRequired = errors.New("ingredient name cannot be empty")
+	// ErrIngredientInUse is returned when an ingredient used by a recipe is deleted or changes unit
+	ErrIngredientInUse = errors.New("ingredient is used by a recipe")
+	// ErrModifierOptionNotFound is returned when the requested modifier option does not exist
+	ErrModifierOptionNotFound = errors.New("modifier option not found")
+	// ErrInvalidRecipe is returned when a recipe lists an ingredient more than once or an unknown ingredient
+	ErrInvalidRecipe = errors.New("invalid recipe")
+)
+
+// IngredientService handles ingredient, recipe and ingredient stock business logic
+type IngredientService struct {
+	ingredientRepo *repository.IngredientRepository
+	menuRepo       *repository.MenuRepository
+	modifierRepo   *repository.ModifierRepository
+	stockNotifier  StockAlertNotifier
+}
+
+// NewIngredientService creates a new IngredientService instance
+func NewIngredientService(ingredientRepo *repository.IngredientRepository, menuRepo *repository.MenuRepository, modifierRepo *repository.ModifierRepository, stockNotifier StockAlertNotifier) *IngredientService {
+	return &IngredientService{
+		ingredientRepo: ingredientRepo,
+		menuRepo:       menuRepo,
+		modifierRepo:   modifierRepo,
+		stockNotifier:  stockNotifier,
+	}
+}
+
+// CreateIngredientRequest represents the create ingredient payload
+// Stock is the opening stock in the base unit and is recorded as a restock
+// movement; StockValue is what the opening stock cost as a whole
+type CreateIngredientRequest struct {
+	Name             string       `json:"name" binding:"required,max=100"`
+	Unit             string       `json:"unit" binding:"required,oneof=g ml pcs"`
+	Stock            int          `json:"stock" binding:"min=0"`
+	StockValue       *money.Money `json:"stock_value" binding:"omitempty,min=0,max=9999999999"`
+	ReorderThreshold int          `json:"reorder_threshold" binding:"min=0"`
+}
+
+// UpdateIngredientRequest represents the update ingredient payload
+// Stock is changed through the receive, adjust and waste endpoints
+type UpdateIngredientRequest struct {
+	Name             string `json:"name" binding:"required,max=100"`
+	Unit             string `json:"unit" binding:"required,oneof=g ml pcs"`
+	ReorderThreshold int    `json:"reorder_threshold" binding:"min=0"`
+}
+
+// IngredientListQuery represents the query parameters of the ingredient listing
+// LowStock limits the list to ingredients at or below their reorder threshold
+type IngredientListQuery struct {
+	LowStock bool `form:"low_stock"`
+}
+
+// RecipeItemRequest represents one ingredient line of a recipe payload
+// Quantity is in the ingredient's base unit per unit sold
+type RecipeItemRequest struct {
+	IngredientID uint `json:"ingredient_id" binding:"required"`
+	Quantity     int  `json:"quantity" binding:"required,min=1"`
+}
+
+// RecipeRequest represents the payload replacing a recipe
+// An empty list removes the recipe
+type RecipeRequest struct {
+	Items []RecipeItemRequest `json:"items" binding:"omitempty,dive"`
+}
+
+// IngredientMovementList is a page of ingredient movements, newest first
+// NextCursor is the cursor of the following page and is nil on the last page
+type IngredientMovementList struct {
+	Movements  []model.IngredientMovement
+	Limit      int
+	NextCursor *uint
+}
+
+// GetAllIngredients retrieves the ingredients matching the query
+func (s *IngredientService) GetAllIngredients(query *IngredientListQuery) ([]model.Ingredient, error) {
+	return s.ingredientRepo.GetAll(query.LowStock)
+}
+
+// GetIngredientByID retrieves an ingredient by ID
+func (s *IngredientService) GetIngredientByID(id uint) (*model.Ingredient, error) {
+	ingredient, err := s.ingredientRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrIngredientNotFound
+		}
+		return nil, err
+	}
+	return ingredient, nil
+}
+
+// CreateIngredient creates a new ingredient
+// The opening stock is recorded as a restock movement by actorID
+func (s *IngredientService) CreateIngredient(actorID uint, req *CreateIngredientRequest) (*model.Ingredient, error) {
+	ingredient := &model.Ingredient{
+		Name:             strings.TrimSpace(req.Name),
+		Unit:             req.Unit,
+		ReorderThreshold: req.ReorderThreshold,
+	}
+	if err := s.ensureUniqueName(ingredient.Name, 0); err != nil {
+		return nil, err
+	}
+
+	tx := s.ingredientRepo.BeginTransaction()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := s.ingredientRepo.Create(tx, ingredient); err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrIngredientNameExists
+		}
+		return nil, err
+	}
+
+	if req.Stock != 0 {
+		movement := &model.IngredientMovement{
+			Type:      model.StockMovementRestock,
+			Quantity:  req.Stock,
+			UserID:    &actorID,
+			Reason:    "Opening stock",
+			TotalCost: req.StockValue,
+		}
+		if err := s.ingredientRepo.ApplyMovement(tx, ingredient, movement); err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("failed to record opening stock: %w", err)
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return ingredient, nil
+}
+
+// UpdateIngredient updates the name, unit and reorder threshold of an ingredient
+// The unit cannot change while recipes use the ingredient, since their
+// quantities are expressed in it
+func (s *IngredientService) UpdateIngredient(id uint, req *UpdateIngredientRequest) (*model.Ingredient, error) {
+	ingredient, err := s.GetIngredientByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if err := s.ensureUniqueName(name, id); err != nil {
+		return nil, err
+	}
+
+	if req.Unit != ingredient.Unit {
+		used, err := s.ingredientRepo.IsUsed(id)
+		if err != nil {
+			return nil, err
+		}
+		if used {
+			return nil, ErrIngredientInUse
+		}
+	}
+
+	ingredient.Name = name
+	ingredient.Unit = req.Unit
+	ingredient.ReorderThreshold = req.ReorderThreshold
+	if err := s.ingredientRepo.Update(ingredient); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrIngredientNameExists
+		}
+		return nil, err
+	}
+
+	return ingredient, nil
+}
+
+// DeleteIngredient deletes an ingredient that no recipe uses
+func (s *IngredientService) DeleteIngredient(id uint) error {
+	if _, err := s.GetIngredientByID(id); err != nil {
+		return err
+	}
+
+	used, err := s.ingredientRepo.IsUsed(id)
+	if err != nil {
+		return err
+	}
+	if used {
+		return ErrIngredientInUse
+	}
+
+	return s.ingredientRepo.Delete(id)
+}
+
+// ReceiveIngredient adds a received delivery to the stock of an ingredient
+// A priced delivery adds its cost to the ingredient's stock value
+func (s *IngredientService) ReceiveIngredient(actorID, id uint, req *ReceiveStockRequest) (*model.IngredientMovement, error) {
+	totalCost := req.TotalCost
+	if req.UnitCost != nil {
+		cost := req.UnitCost.Mul(req.Quantity)
+		totalCost = &cost
+	}
+
+	movement := &model.IngredientMovement{
+		Type:            model.StockMovementRestock,
+		Quantity:        req.Quantity,
+		UserID:          &actorID,
+		Reason:          strings.TrimSpace(req.Reason),
+		UnitCost:        req.UnitCost,
+		TotalCost:       totalCost,
+		Supplier:        strings.TrimSpace(req.Supplier),
+		ReferenceNumber: strings.TrimSpace(req.ReferenceNumber),
+	}
+	return s.applyMovement(id, movement)
+}
+
+// AdjustIngredient applies a manual stock correction to an ingredient
+func (s *IngredientService) AdjustIngredient(actorID, id uint, req *AdjustStockRequest) (*model.IngredientMovement, error) {
+	movement := &model.IngredientMovement{
+		Type:     model.StockMovementAdjustment,
+		Quantity: req.Quantity,
+		UserID:   &actorID,
+		Reason:   strings.TrimSpace(req.Reason),
+	}
+	if movement.Reason == "" {
+		return nil, ErrStockReasonRequired
+	}
+	return s.applyMovement(id, movement)
+}
+
+// WasteIngredient writes off wasted or spoiled stock of an ingredient
+func (s *IngredientService) WasteIngredient(actorID, id uint, req *WasteStockRequest) (*model.IngredientMovement, error) {
+	movement := &model.IngredientMovement{
+		Type:     model.StockMovementWaste,
+		Quantity: -req.Quantity,
+		UserID:   &actorID,
+		Reason:   strings.TrimSpace(req.Reason),
+	}
+	if movement.Reason == "" {
+		return nil, ErrStockReasonRequired
+	}
+	return s.applyMovement(id, movement)
+}
+
+// applyMovement applies a stock movement to an ingredient under a row lock, so
+// it is serialized with concurrent checkouts using the ingredient
+func (s *IngredientService) applyMovement(id uint, movement *model.IngredientMovement) (*model.IngredientMovement, error) {
+	tx := s.ingredientRepo.BeginTransaction()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	ingredient, err := s.ingredientRepo.FindByIDWithLock(tx, id)
+	if err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrIngredientNotFound
+		}
+		return nil, err
+	}
+
+	if ingredient.Stock+movement.Quantity < 0 {
+		tx.Rollback()
+		return nil, fmt.Errorf("%w: '%s' has %d %s in stock", ErrNegativeStock, ingredient.Name, ingredient.Stock, ingredient.Unit)
+	}
+
+	if err := s.ingredientRepo.ApplyMovement(tx, ingredient, movement); err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to update stock: %w", err)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	if alert, ok := ingredientStockAlertFor(ingredient, movement); ok {
+		s.stockNotifier.NotifyStockAlerts([]StockAlert{alert})
+	}
+
+	return movement, nil
+}
+
+// ListIngredientMovements retrieves a page of the stock ledger of an ingredient
+func (s *IngredientService) ListIngredientMovements(id uint, query *StockMovementQuery) (*IngredientMovementList, error) {
+	if _, err := s.GetIngredientByID(id); err != nil {
+		return nil, err
+	}
+
+	filter := repository.IngredientMovementFilter{
+		IngredientID: id,
+		Type:         query.Type,
+		Cursor:       query.Cursor,
+		Limit:        query.Limit,
+	}
+	if filter.Limit == 0 {
+		filter.Limit = DefaultStockMovementPageSize
+	}
+
+	var err error
+	if filter.From, filter.To, err = parseQueryTimeRange(query.From, query.To); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidStockQuery, err)
+	}
+
+	// Fetch one extra row to know whether another page follows
+	limit := filter.Limit
+	filter.Limit = limit + 1
+
+	movements, err := s.ingredientRepo.ListMovements(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	list := &IngredientMovementList{Movements: movements, Limit: limit}
+	if len(movements) > limit {
+		list.Movements = movements[:limit]
+		next := list.Movements[limit-1].ID
+		list.NextCursor = &next
+	}
+	return list, nil
+}
+
+// SetMenuRecipe replaces the recipe of a menu item
+// Once a menu item has a recipe, checkout deducts its ingredients instead of its own stock
+func (s *IngredientService) SetMenuRecipe(menuID uint, req *RecipeRequest) (*model.Menu, error) {
+	if _, err := s.menuRepo.FindByID(menuID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMenuNotFound
+		}
+		return nil, err
+	}
+
+	if err := s.validateRecipe(req); err != nil {
+		return nil, err
+	}
+
+	recipe := make([]model.MenuIngredient, len(req.Items))
+	for i, item := range req.Items {
+		recipe[i] = model.MenuIngredient{IngredientID: item.IngredientID, Quantity: item.Quantity}
+	}
+	if err := s.ingredientRepo.ReplaceMenuRecipe(menuID, recipe); err != nil {
+		return nil, err
+	}
+
+	return s.menuRepo.FindByID(menuID)
+}
+
+// GetOptionRecipe retrieves the recipe of a modifier option
+func (s *IngredientService) GetOptionRecipe(optionID uint) ([]model.ModifierOptionIngredient, error) {
+	if err := s.ensureOptionExists(optionID); err != nil {
+		return nil, err
+	}
+	return s.ingredientRepo.GetOptionRecipe(optionID)
+}
+
+// SetOptionRecipe replaces the recipe of a modifier option
+// Its ingredients are deducted in addition to the menu item's recipe whenever the option is chosen
+func (s *IngredientService) SetOptionRecipe(optionID uint, req *RecipeRequest) ([]model.ModifierOptionIngredient, error) {
+	if err := s.ensureOptionExists(optionID); err != nil {
+		return nil, err
+	}
+
+	if err := s.validateRecipe(req); err != nil {
+		return nil, err
+	}
+
+	recipe := make([]model.ModifierOptionIngredient, len(req.Items))
+	for i, item := range req.Items {
+		recipe[i] = model.ModifierOptionIngredient{IngredientID: item.IngredientID, Quantity: item.Quantity}
+	}
+	if err := s.ingredientRepo.ReplaceOptionRecipe(optionID, recipe); err != nil {
+		return nil, err
+	}
+
+	return s.ingredientRepo.GetOptionRecipe(optionID)
+}
+
+// ensureOptionExists returns ErrModifierOptionNotFound if the modifier option does not exist
+func (s *IngredientService) ensureOptionExists(optionID uint) error {
+	if _, err := s.modifierRepo.FindOptionByID(optionID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrModifierOptionNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// validateRecipe checks that every recipe line names a distinct, existing ingredient
+func (s *IngredientService) validateRecipe(req *RecipeRequest) error {
+	ids := make([]uint, 0, len(req.Items))
+	seen := make(map[uint]bool, len(req.Items))
+	for _, item := range req.Items {
+		if seen[item.IngredientID] {
+			return fmt.Errorf("%w: ingredient %d is listed more than once", ErrInvalidRecipe, item.IngredientID)
+		}
+		seen[item.IngredientID] = true
+		ids = append(ids, item.IngredientID)
+	}
+
+	ingredients, err := s.ingredientRepo.FindByIDs(ids)
+	if err != nil {
+		return err
+	}
+	if len(ingredients) != len(ids) {
+		return fmt.Errorf("%w: unknown ingredient", ErrInvalidRecipe)
+	}
+	return nil
+}
+
+// ensureUniqueName returns ErrIngredientNameExists if another ingredient uses the name
+func (s *IngredientService) ensureUniqueName(name string, excludeID uint) error {
+	if name == "" {
+		return ErrIngredientNameRequired
+	}
+
+	exists, err := s.ingredientRepo.ExistsByName(name, excludeID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrIngredientNameExists
+	}
+	return nil
+}
+
+// ingredientUsage maps ingredient IDs to quantities in their base unit
+type ingredientUsage map[uint]int
+
+// add adds quantity × multiplier of an ingredient
+func (u ingredientUsage) add(ingredientID uint, quantity, multiplier int) {
+	u[ingredientID] += quantity * multiplier
+}
+
+// ids returns the ingredient IDs in ascending order, the order rows are locked in
+func (u ingredientUsage) ids() []uint {
+	ids := make([]uint, 0, len(u))
+	for id := range u {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
@@ -0,0 +1,284 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"service-cashier/internal/model"
+	"service-cashier/internal/repository"
+	"service-cashier/pkg/money"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrInvalidStockQuery is returned when the stock movement listing parameters are inconsistent
+	ErrInvalidStockQuery = errors.New("invalid stock movement query")
+	// ErrStockReasonRequired is returned when a stock correction or write-off has no reason
+	ErrStockReasonRequired = errors.New("reason cannot be empty")
+	// ErrNegativeStock is returned when a stock change would take stock below zero
+	ErrNegativeStock = errors.New("stock cannot go below zero")
+	// ErrMenuHasRecipe is returned when stock is moved on a menu item made from a recipe
+	ErrMenuHasRecipe = errors.New("menu item is made from a recipe and has no stock of its own")
+)
+
+// InventoryService handles stock ledger business logic
+type InventoryService struct {
+	menuRepo          *repository.MenuRepository
+	stockMovementRepo *repository.StockMovementRepository
+	ingredientRepo    *repository.IngredientRepository
+	stockNotifier     StockAlertNotifier
+}
+
+// NewInventoryService creates a new InventoryService instance
+func NewInventoryService(menuRepo *repository.MenuRepository, stockMovementRepo *repository.StockMovementRepository, ingredientRepo *repository.IngredientRepository, stockNotifier StockAlertNotifier) *InventoryService {
+	return &InventoryService{
+		menuRepo:          menuRepo,
+		stockMovementRepo: stockMovementRepo,
+		ingredientRepo:    ingredientRepo,
+		stockNotifier:     stockNotifier,
+	}
+}
+
+// StockMovementQuery represents the query parameters of the stock movement listing
+// From and To accept a date (2006-01-02) or an RFC 3339 timestamp; a date in To
+// includes the whole day
+type StockMovementQuery struct {
+	From   string `form:"from"`
+	To     string `form:"to"`
+	Type   string `form:"type" binding:"omitempty,oneof=sale refund void restock adjustment waste stock_take"`
+	Cursor uint   `form:"cursor"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+// DefaultStockMovementPageSize is the page size used when no limit is given
+const DefaultStockMovementPageSize = 50
+
+// StockMovementList is a page of stock movements, newest first
+// NextCursor is the cursor of the following page and is nil on the last page
+type StockMovementList struct {
+	Movements  []model.StockMovement
+	Limit      int
+	NextCursor *uint
+}
+
+// ListStockMovements retrieves a page of the stock ledger of a menu item
+// Archived menu items keep their history
+func (s *InventoryService) ListStockMovements(menuID uint, query *StockMovementQuery) (*StockMovementList, error) {
+	if _, err := s.menuRepo.FindByIDUnscoped(menuID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMenuNotFound
+		}
+		return nil, err
+	}
+
+	filter := repository.StockMovementFilter{
+		MenuID: menuID,
+		Type:   query.Type,
+		Cursor: query.Cursor,
+		Limit:  query.Limit,
+	}
+	if filter.Limit == 0 {
+		filter.Limit = DefaultStockMovementPageSize
+	}
+
+	var err error
+	if filter.From, filter.To, err = parseQueryTimeRange(query.From, query.To); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidStockQuery, err)
+	}
+
+	// Fetch one extra row to know whether another page follows
+	limit := filter.Limit
+	filter.Limit = limit + 1
+
+	movements, err := s.stockMovementRepo.List(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	list := &StockMovementList{Movements: movements, Limit: limit}
+	if len(movements) > limit {
+		list.Movements = movements[:limit]
+		next := list.Movements[limit-1].ID
+		list.NextCursor = &next
+	}
+	return list, nil
+}
+
+// ReceiveStockRequest represents the payload of a received delivery
+// The delivery is priced by either UnitCost or TotalCost, the cost of the whole
+// quantity; TotalCost suits ingredients whose cost per base unit is below a cent
+type ReceiveStockRequest struct {
+	Quantity        int          `json:"quantity" binding:"required,min=1"`
+	UnitCost        *money.Money `json:"unit_cost" binding:"omitempty,min=0,max=9999999999"`
+	TotalCost       *money.Money `json:"total_cost" binding:"omitempty,excluded_with=UnitCost,min=0,max=9999999999"`
+	Supplier        string       `json:"supplier" binding:"max=100"`
+	ReferenceNumber string       `json:"reference_number" binding:"max=100"`
+	Reason          string       `json:"reason" binding:"max=255"`
+}
+
+// AdjustStockRequest represents the payload of a manual stock correction
+// Quantity is signed: positive adds stock and negative removes it
+type AdjustStockRequest struct {
+	Quantity int    `json:"quantity" binding:"required"`
+	Reason   string `json:"reason" binding:"required,max=255"`
+}
+
+// WasteStockRequest represents the payload of a waste or spoilage write-off
+type WasteStockRequest struct {
+	Quantity int    `json:"quantity" binding:"required,min=1"`
+	Reason   string `json:"reason" binding:"required,max=255"`
+}
+
+// ReceiveStock adds a received delivery to the stock of a menu item
+// A priced delivery moves the item's cost price to the new weighted average
+func (s *InventoryService) ReceiveStock(actorID, menuID uint, req *ReceiveStockRequest) (*model.StockMovement, error) {
+	unitCost := req.UnitCost
+	if req.TotalCost != nil {
+		cost := req.TotalCost.Prorate(1, req.Quantity)
+		unitCost = &cost
+	}
+
+	movement := &model.StockMovement{
+		Type:            model.StockMovementRestock,
+		Quantity:        req.Quantity,
+		UserID:          &actorID,
+		Reason:          strings.TrimSpace(req.Reason),
+		UnitCost:        unitCost,
+		Supplier:        strings.TrimSpace(req.Supplier),
+		ReferenceNumber: strings.TrimSpace(req.ReferenceNumber),
+	}
+	return s.applyMovement(menuID, movement)
+}
+
+// AdjustStock applies a manual stock correction to a menu item
+func (s *InventoryService) AdjustStock(actorID, menuID uint, req *AdjustStockRequest) (*model.StockMovement, error) {
+	movement := &model.StockMovement{
+		Type:     model.StockMovementAdjustment,
+		Quantity: req.Quantity,
+		UserID:   &actorID,
+		Reason:   strings.TrimSpace(req.Reason),
+	}
+	if movement.Reason == "" {
+		return nil, ErrStockReasonRequired
+	}
+	return s.applyMovement(menuID, movement)
+}
+
+// WasteStock writes off wasted or spoiled stock of a menu item
+func (s *InventoryService) WasteStock(actorID, menuID uint, req *WasteStockRequest) (*model.StockMovement, error) {
+	movement := &model.StockMovement{
+		Type:     model.StockMovementWaste,
+		Quantity: -req.Quantity,
+		UserID:   &actorID,
+		Reason:   strings.TrimSpace(req.Reason),
+	}
+	if movement.Reason == "" {
+		return nil, ErrStockReasonRequired
+	}
+	return s.applyMovement(menuID, movement)
+}
+
+// applyMovement applies a stock movement to a menu item under a row lock, so
+// it is serialized with concurrent checkouts of the same item
+// Items made from a recipe are rejected, since checkout draws down their
+// ingredients and never reads their own stock
+func (s *InventoryService) applyMovement(menuID uint, movement *model.StockMovement) (*model.StockMovement, error) {
+	tx := s.menuRepo.BeginTransaction()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	menu, err := s.menuRepo.FindByIDWithLock(tx, menuID)
+	if err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMenuNotFound
+		}
+		return nil, err
+	}
+
+	if menu.Recipe, err = s.ingredientRepo.GetMenuRecipe(tx, menu.ID); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if menu.HasRecipe() {
+		tx.Rollback()
+		return nil, fmt.Errorf("%w: move the stock of the ingredients of '%s' instead", ErrMenuHasRecipe, menu.Name)
+	}
+
+	if menu.Stock+movement.Quantity < 0 {
+		tx.Rollback()
+		return nil, fmt.Errorf("%w: '%s' has %d in stock", ErrNegativeStock, menu.Name, menu.Stock)
+	}
+
+	if err := s.menuRepo.ApplyStockMovement(tx, menu, movement); err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to update stock: %w", err)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	if alert, ok := stockAlertFor(menu, movement); ok {
+		s.stockNotifier.NotifyStockAlerts([]StockAlert{alert})
+	}
+
+	return movement, nil
+}
+
+// LowStockItem is a menu item at or below its reorder threshold
+// Level is low, or sold_out when no stock is left
+type LowStockItem struct {
+	model.Menu
+	Level string `json:"level"`
+}
+
+// LowStockIngredient is an ingredient at or below its reorder threshold
+// Level is low, or sold_out when no stock is left
+type LowStockIngredient struct {
+	model.Ingredient
+	Level string `json:"level"`
+}
+
+// LowStockReport lists the menu items and ingredients that need restocking
+// Items made from a recipe appear through their ingredients
+type LowStockReport struct {
+	Menus       []LowStockItem       `json:"menus"`
+	Ingredients []LowStockIngredient `json:"ingredients"`
+}
+
+// GetLowStock retrieves the menu items and ingredients that need restocking, lowest stock first
+func (s *InventoryService) GetLowStock() (*LowStockReport, error) {
+	menus, err := s.menuRepo.GetLowStock()
+	if err != nil {
+		return nil, err
+	}
+	ingredients, err := s.ingredientRepo.GetAll(true)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &LowStockReport{
+		Menus:       make([]LowStockItem, len(menus)),
+		Ingredients: make([]LowStockIngredient, len(ingredients)),
+	}
+	for i, menu := range menus {
+		report.Menus[i] = LowStockItem{Menu: menu, Level: lowStockLevel(menu.Stock)}
+	}
+	for i, ingredient := range ingredients {
+		report.Ingredients[i] = LowStockIngredient{Ingredient: ingredient, Level: lowStockLevel(ingredient.Stock)}
+	}
+	return report, nil
+}
+
+// lowStockLevel returns the alert level of stock already known to be at or below its threshold
+func lowStockLevel(stock int) string {
+	if stock <= 0 {
+		return StockAlertSoldOut
+	}
+	return StockAlertLow
+}
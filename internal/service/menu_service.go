@@ -1,41 +1,470 @@
 package service
 
 import (
+	"errors"
+	"fmt"
 	"service-cashier/internal/model"
 	"service-cashier/internal/repository"
+	"service-cashier/pkg/money"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrMenuNotFound is returned when the requested menu item does not exist
+	ErrMenuNotFound = errors.New("menu item not found")
+	// ErrMenuNameExists is returned when another menu item already uses the name
+	ErrMenuNameExists = errors.New("menu item with this name already exists")
+	// ErrMenuNameRequired is returned when the menu name is blank after trimming
+	ErrMenuNameRequired = errors.New("menu name cannot be empty")
+	// ErrInvalidMenuQuery is returned when the menu listing parameters are inconsistent
+	ErrInvalidMenuQuery = errors.New("invalid menu query")
 )
 
 // MenuService handles menu business logic
 type MenuService struct {
-	menuRepo *repository.MenuRepository
+	menuRepo     *repository.MenuRepository
+	categoryRepo *repository.CategoryRepository
+	modifierRepo *repository.ModifierRepository
 }
 
 // NewMenuService creates a new MenuService instance
-func NewMenuService(menuRepo *repository.MenuRepository) *MenuService {
-	return &MenuService{menuRepo: menuRepo}
+func NewMenuService(menuRepo *repository.MenuRepository, categoryRepo *repository.CategoryRepository, modifierRepo *repository.ModifierRepository) *MenuService {
+	return &MenuService{
+		menuRepo:     menuRepo,
+		categoryRepo: categoryRepo,
+		modifierRepo: modifierRepo,
+	}
+}
+
+// MenuRequest represents the create and full update menu payload
+// An omitted reorder_threshold or cost_price keeps the current value; cost_price
+// is also moved to the weighted average cost by deliveries received with a unit cost
+type MenuRequest struct {
+	Name             string       `json:"name" binding:"required,max=100"`
+	Price            *money.Money `json:"price" binding:"required,min=0,max=9999999999"`
+	CostPrice        *money.Money `json:"cost_price" binding:"omitempty,min=0,max=9999999999"`
+	Stock            *int         `json:"stock" binding:"required,min=0"`
+	Image            string       `json:"image" binding:"max=255"`
+	TaxExempt        bool         `json:"tax_exempt"`
+	CategoryID       *uint        `json:"category_id"`
+	ReorderThreshold *int         `json:"reorder_threshold" binding:"omitempty,min=0"`
+}
+
+// PatchMenuRequest represents the partial update menu payload
+// Only the fields present in the request body are applied; a category_id of 0
+// removes the menu item from its category
+type PatchMenuRequest struct {
+	Name             *string      `json:"name" binding:"omitempty,min=1,max=100"`
+	Price            *money.Money `json:"price" binding:"omitempty,min=0,max=9999999999"`
+	CostPrice        *money.Money `json:"cost_price" binding:"omitempty,min=0,max=9999999999"`
+	Stock            *int         `json:"stock" binding:"omitempty,min=0"`
+	Image            *string      `json:"image" binding:"omitempty,max=255"`
+	TaxExempt        *bool        `json:"tax_exempt"`
+	CategoryID       *uint        `json:"category_id"`
+	ReorderThreshold *int         `json:"reorder_threshold" binding:"omitempty,min=0"`
+}
+
+// MenuModifierGroupsRequest represents the payload assigning modifier groups to a menu item
+// An empty list removes every modifier group from the menu item
+type MenuModifierGroupsRequest struct {
+	ModifierGroupIDs []uint `json:"modifier_group_ids" binding:"omitempty,dive,required"`
+}
+
+// MenuCategoryGroup is a category together with its menu items
+// Category is nil for the group of uncategorized items
+type MenuCategoryGroup struct {
+	Category *model.Category `json:"category"`
+	Menus    []model.Menu    `json:"menus"`
+}
+
+// MenuListQuery represents the query parameters of the menu listing
+// Prices are decimal strings such as 15000 or 15000.50. The listing is always
+// paginated: Page defaults to 1 and Limit to DefaultMenuPageSize. Grouped groups
+// the items of the requested page by category, so a category can continue on
+// the next page. Archived lists archived items
+type MenuListQuery struct {
+	Archived   bool   `form:"archived"`
+	CategoryID *uint  `form:"category_id"`
+	Search     string `form:"search" binding:"max=100"`
+	InStock    bool   `form:"in_stock"`
+	Available  bool   `form:"available"`
+	MinPrice   string `form:"min_price"`
+	MaxPrice   string `form:"max_price"`
+	Sort       string `form:"sort" binding:"omitempty,oneof=name price stock created_at"`
+	Order      string `form:"order" binding:"omitempty,oneof=asc desc"`
+	Page       int    `form:"page" binding:"omitempty,min=1"`
+	Limit      int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	Grouped    bool   `form:"grouped"`
+}
+
+// DefaultMenuPageSize is the page size used when no limit is given
+const DefaultMenuPageSize = 20
+
+// MenuList is a page of menu items with the total number of matching items
+type MenuList struct {
+	Menus []model.Menu
+	Total int64
+	Page  int
+	Limit int
 }
 
-// GetAllMenus retrieves all menu items
-func (s *MenuService) GetAllMenus() ([]model.Menu, error) {
-	return s.menuRepo.GetAll()
+// GetAllMenus retrieves the menu items matching the query
+func (s *MenuService) GetAllMenus(query *MenuListQuery) (*MenuList, error) {
+	filter := repository.MenuFilter{
+		Archived:      query.Archived,
+		CategoryID:    query.CategoryID,
+		Search:        strings.TrimSpace(query.Search),
+		InStockOnly:   query.InStock,
+		AvailableOnly: query.Available,
+		SortBy:        query.Sort,
+		SortDesc:      query.Order == "desc",
+	}
+
+	var err error
+	if filter.MinPrice, err = parseQueryAmount("min_price", query.MinPrice); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMenuQuery, err)
+	}
+	if filter.MaxPrice, err = parseQueryAmount("max_price", query.MaxPrice); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMenuQuery, err)
+	}
+	if filter.MinPrice != nil && filter.MaxPrice != nil && *filter.MinPrice > *filter.MaxPrice {
+		return nil, fmt.Errorf("%w: min_price cannot exceed max_price", ErrInvalidMenuQuery)
+	}
+
+	// The listing is always paginated so large catalogues are never sent whole
+	page := max(query.Page, 1)
+	filter.Limit = query.Limit
+	if filter.Limit == 0 {
+		filter.Limit = DefaultMenuPageSize
+	}
+	filter.Offset = (page - 1) * filter.Limit
+
+	menus, total, err := s.menuRepo.GetAll(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MenuList{
+		Menus: menus,
+		Total: total,
+		Page:  page,
+		Limit: filter.Limit,
+	}, nil
+}
+
+// GroupMenusByCategory groups menu items that are sorted by category
+// Groups follow the order of the items, so category display order is kept
+// when the listing is not sorted by another field
+func GroupMenusByCategory(menus []model.Menu) []MenuCategoryGroup {
+	groups := []MenuCategoryGroup{}
+	for _, menu := range menus {
+		last := len(groups) - 1
+		if last < 0 || !sameCategory(groups[last].Category, menu.Category) {
+			groups = append(groups, MenuCategoryGroup{Category: menu.Category})
+			last++
+		}
+		groups[last].Menus = append(groups[last].Menus, menu)
+	}
+	return groups
+}
+
+// sameCategory reports whether two possibly nil categories are the same category
+func sameCategory(a, b *model.Category) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.ID == b.ID
 }
 
 // GetMenuByID retrieves a menu item by ID
 func (s *MenuService) GetMenuByID(id uint) (*model.Menu, error) {
-	return s.menuRepo.FindByID(id)
+	menu, err := s.menuRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMenuNotFound
+		}
+		return nil, err
+	}
+	return menu, nil
 }
 
 // CreateMenu creates a new menu item
-func (s *MenuService) CreateMenu(menu *model.Menu) error {
-	return s.menuRepo.Create(menu)
+// The opening stock is recorded as a restock movement by actorID
+func (s *MenuService) CreateMenu(actorID uint, req *MenuRequest) (*model.Menu, error) {
+	menu := &model.Menu{
+		Name:      strings.TrimSpace(req.Name),
+		Price:     *req.Price,
+		Image:     strings.TrimSpace(req.Image),
+		TaxExempt: req.TaxExempt,
+	}
+	if req.ReorderThreshold != nil {
+		menu.ReorderThreshold = *req.ReorderThreshold
+	}
+	if req.CostPrice != nil {
+		menu.CostPrice = *req.CostPrice
+	}
+
+	if err := s.setCategory(menu, req.CategoryID); err != nil {
+		return nil, err
+	}
+
+	if err := s.ensureUniqueName(menu.Name, 0); err != nil {
+		return nil, err
+	}
+
+	tx := s.menuRepo.BeginTransaction()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := s.menuRepo.Create(tx, menu); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if *req.Stock != 0 {
+		movement := &model.StockMovement{
+			Type:     model.StockMovementRestock,
+			Quantity: *req.Stock,
+			UserID:   &actorID,
+			Reason:   "Opening stock",
+		}
+		if err := s.menuRepo.ApplyStockMovement(tx, menu, movement); err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("failed to record opening stock: %w", err)
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return menu, nil
 }
 
-// UpdateMenu updates an existing menu item
-func (s *MenuService) UpdateMenu(menu *model.Menu) error {
-	return s.menuRepo.Update(menu)
+// UpdateMenu replaces all editable fields of an existing menu item
+// A changed stock level is recorded as an adjustment movement by actorID
+func (s *MenuService) UpdateMenu(actorID, id uint, req *MenuRequest) (*model.Menu, error) {
+	return s.saveMenu(actorID, id, req.Stock, func(menu *model.Menu) error {
+		menu.Name = strings.TrimSpace(req.Name)
+		menu.Price = *req.Price
+		menu.Image = strings.TrimSpace(req.Image)
+		menu.TaxExempt = req.TaxExempt
+		if req.ReorderThreshold != nil {
+			menu.ReorderThreshold = *req.ReorderThreshold
+		}
+		if req.CostPrice != nil {
+			menu.CostPrice = *req.CostPrice
+		}
+		return s.setCategory(menu, req.CategoryID)
+	})
 }
 
-// DeleteMenu deletes a menu item by ID
+// PatchMenu applies a partial update to an existing menu item
+// A changed stock level is recorded as an adjustment movement by actorID
+func (s *MenuService) PatchMenu(actorID, id uint, req *PatchMenuRequest) (*model.Menu, error) {
+	return s.saveMenu(actorID, id, req.Stock, func(menu *model.Menu) error {
+		if req.Name != nil {
+			menu.Name = strings.TrimSpace(*req.Name)
+		}
+		if req.Price != nil {
+			menu.Price = *req.Price
+		}
+		if req.CostPrice != nil {
+			menu.CostPrice = *req.CostPrice
+		}
+		if req.Image != nil {
+			menu.Image = strings.TrimSpace(*req.Image)
+		}
+		if req.TaxExempt != nil {
+			menu.TaxExempt = *req.TaxExempt
+		}
+		if req.ReorderThreshold != nil {
+			menu.ReorderThreshold = *req.ReorderThreshold
+		}
+		if req.CategoryID != nil {
+			categoryID := req.CategoryID
+			if *categoryID == 0 {
+				categoryID = nil
+			}
+			if err := s.setCategory(menu, categoryID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// SetModifierGroups replaces the modifier groups offered with a menu item
+func (s *MenuService) SetModifierGroups(id uint, req *MenuModifierGroupsRequest) (*model.Menu, error) {
+	menu, err := s.GetMenuByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := s.modifierRepo.FindByIDs(req.ModifierGroupIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[uint]bool, len(groups))
+	for _, group := range groups {
+		found[group.ID] = true
+	}
+	for _, groupID := range req.ModifierGroupIDs {
+		if !found[groupID] {
+			return nil, ErrModifierGroupNotFound
+		}
+	}
+
+	if err := s.menuRepo.ReplaceModifierGroups(menu, groups); err != nil {
+		return nil, err
+	}
+
+	return s.GetMenuByID(id)
+}
+
+// SetMenuDisabled takes a menu item off sale ("86" it) or puts it back on sale
+// Stock is left unchanged, so the item returns with the stock it had
+func (s *MenuService) SetMenuDisabled(id uint, disabled bool) (*model.Menu, error) {
+	if _, err := s.GetMenuByID(id); err != nil {
+		return nil, err
+	}
+
+	if err := s.menuRepo.SetDisabled(id, disabled); err != nil {
+		return nil, err
+	}
+
+	return s.GetMenuByID(id)
+}
+
+// DeleteMenu archives a menu item by ID
+// Archived items disappear from the menu and checkout but stay linked to past sales
 func (s *MenuService) DeleteMenu(id uint) error {
+	if _, err := s.GetMenuByID(id); err != nil {
+		return err
+	}
+
 	return s.menuRepo.Delete(id)
 }
+
+// RestoreMenu brings an archived menu item back onto the menu
+// Restoring an item that is not archived is a no-op
+func (s *MenuService) RestoreMenu(id uint) (*model.Menu, error) {
+	menu, err := s.menuRepo.FindByIDUnscoped(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMenuNotFound
+		}
+		return nil, err
+	}
+
+	if menu.DeletedAt.Valid {
+		// Another live item may have taken the name while this one was archived
+		if err := s.ensureUniqueName(menu.Name, menu.ID); err != nil {
+			return nil, err
+		}
+		if err := s.menuRepo.Restore(id); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.GetMenuByID(id)
+}
+
+// setCategory assigns the menu item to a category, or to none when categoryID is nil
+func (s *MenuService) setCategory(menu *model.Menu, categoryID *uint) error {
+	menu.CategoryID = nil
+	menu.Category = nil
+	if categoryID == nil {
+		return nil
+	}
+
+	category, err := s.categoryRepo.FindByID(*categoryID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrCategoryNotFound
+		}
+		return err
+	}
+
+	menu.CategoryID = &category.ID
+	menu.Category = category
+	return nil
+}
+
+// saveMenu applies edit to a menu item and persists it
+// The edit is applied to the row read under its lock, so columns the request
+// does not change, such as the weighted average cost moved by deliveries or the
+// disabled flag, keep their latest values. When stock is set and differs from
+// the current level, the difference is recorded as an adjustment movement
+func (s *MenuService) saveMenu(actorID, id uint, stock *int, edit func(menu *model.Menu) error) (*model.Menu, error) {
+	tx := s.menuRepo.BeginTransaction()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	menu, err := s.menuRepo.FindByIDWithLock(tx, id)
+	if err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMenuNotFound
+		}
+		return nil, err
+	}
+
+	if err := edit(menu); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := s.ensureUniqueName(menu.Name, menu.ID); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := s.menuRepo.Update(tx, menu); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if stock != nil && *stock != menu.Stock {
+		movement := &model.StockMovement{
+			Type:     model.StockMovementAdjustment,
+			Quantity: *stock - menu.Stock,
+			UserID:   &actorID,
+			Reason:   "Stock edited on menu item",
+		}
+		if err := s.menuRepo.ApplyStockMovement(tx, menu, movement); err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("failed to record stock adjustment: %w", err)
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return s.GetMenuByID(id)
+}
+
+// ensureUniqueName returns ErrMenuNameExists if another menu item uses the name
+func (s *MenuService) ensureUniqueName(name string, excludeID uint) error {
+	if name == "" {
+		return ErrMenuNameRequired
+	}
+
+	exists, err := s.menuRepo.ExistsByName(name, excludeID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrMenuNameExists
+	}
+	return nil
+}
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"service-cashier/internal/model"
+	"service-cashier/internal/repository"
+	"service-cashier/pkg/money"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrModifierGroupNotFound is returned when the requested modifier group does not exist
+	ErrModifierGroupNotFound = errors.New("modifier group not found")
+	// ErrInvalidModifierGroup is returned when a modifier group definition is inconsistent
+	ErrInvalidModifierGroup = errors.New("invalid modifier group")
+	// ErrInvalidModifierSelection is returned when the modifiers chosen at checkout do not fit the menu item
+	ErrInvalidModifierSelection = errors.New("invalid modifier selection")
+)
+
+// ModifierService handles modifier group business logic
+type ModifierService struct {
+	modifierRepo *repository.ModifierRepository
+}
+
+// NewModifierService creates a new ModifierService instance
+func NewModifierService(modifierRepo *repository.ModifierRepository) *ModifierService {
+	return &ModifierService{modifierRepo: modifierRepo}
+}
+
+// ModifierOptionRequest represents a single option of a modifier group payload
+// ID is set to keep an existing option when updating a group
+type ModifierOptionRequest struct {
+	ID           uint        `json:"id"`
+	Name         string      `json:"name" binding:"required,max=50"`
+	PriceDelta   money.Money `json:"price_delta" binding:"min=-9999999999,max=9999999999"`
+	DisplayOrder int         `json:"display_order"`
+	Active       *bool       `json:"active"`
+}
+
+// ModifierGroupRequest represents the create and update modifier group payload
+// MaxSelections of 0 means no limit for multi-select groups; single-select groups
+// always allow exactly one option at most
+type ModifierGroupRequest struct {
+	Name          string                  `json:"name" binding:"required,max=50"`
+	SelectionType string                  `json:"selection_type" binding:"required,oneof=single multi"`
+	MinSelections int                     `json:"min_selections" binding:"min=0"`
+	MaxSelections int                     `json:"max_selections" binding:"min=0"`
+	Active        *bool                   `json:"active"`
+	Options       []ModifierOptionRequest `json:"options" binding:"required,min=1,dive"`
+}
+
+// GetAllModifierGroups retrieves all modifier groups with their options
+func (s *ModifierService) GetAllModifierGroups() ([]model.ModifierGroup, error) {
+	return s.modifierRepo.GetAll()
+}
+
+// GetModifierGroupByID retrieves a modifier group by ID
+func (s *ModifierService) GetModifierGroupByID(id uint) (*model.ModifierGroup, error) {
+	group, err := s.modifierRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrModifierGroupNotFound
+		}
+		return nil, err
+	}
+	return group, nil
+}
+
+// CreateModifierGroup creates a new modifier group with its options
+func (s *ModifierService) CreateModifierGroup(req *ModifierGroupRequest) (*model.ModifierGroup, error) {
+	group := &model.ModifierGroup{Active: true}
+	if err := applyModifierGroupRequest(group, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.modifierRepo.Create(group); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+// UpdateModifierGroup replaces the definition and options of a modifier group
+// Past sales are unaffected because transactions keep a snapshot of the chosen options
+func (s *ModifierService) UpdateModifierGroup(id uint, req *ModifierGroupRequest) (*model.ModifierGroup, error) {
+	group, err := s.GetModifierGroupByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyModifierGroupRequest(group, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.modifierRepo.Update(group); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+// DeleteModifierGroup deletes a modifier group and detaches it from every menu item
+func (s *ModifierService) DeleteModifierGroup(id uint) error {
+	if _, err := s.GetModifierGroupByID(id); err != nil {
+		return err
+	}
+	return s.modifierRepo.Delete(id)
+}
+
+// applyModifierGroupRequest validates the request and copies it onto the group
+func applyModifierGroupRequest(group *model.ModifierGroup, req *ModifierGroupRequest) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return fmt.Errorf("%w: name cannot be empty", ErrInvalidModifierGroup)
+	}
+
+	maxSelections := req.MaxSelections
+	if req.SelectionType == model.ModifierSelectionSingle {
+		maxSelections = 1
+	}
+	if maxSelections > 0 && req.MinSelections > maxSelections {
+		return fmt.Errorf("%w: min_selections cannot exceed max_selections", ErrInvalidModifierGroup)
+	}
+
+	// Existing options may only be kept by groups that own them
+	existing := make(map[uint]model.ModifierOption, len(group.Options))
+	for _, option := range group.Options {
+		existing[option.ID] = option
+	}
+
+	options := make([]model.ModifierOption, 0, len(req.Options))
+	for _, optionReq := range req.Options {
+		option := model.ModifierOption{Active: true}
+		if optionReq.ID != 0 {
+			current, ok := existing[optionReq.ID]
+			if !ok {
+				return fmt.Errorf("%w: option %d does not belong to this group", ErrInvalidModifierGroup, optionReq.ID)
+			}
+			option = current
+		}
+
+		option.Name = strings.TrimSpace(optionReq.Name)
+		if option.Name == "" {
+			return fmt.Errorf("%w: option name cannot be empty", ErrInvalidModifierGroup)
+		}
+		option.PriceDelta = optionReq.PriceDelta
+		option.DisplayOrder = optionReq.DisplayOrder
+		if optionReq.Active != nil {
+			option.Active = *optionReq.Active
+		}
+		options = append(options, option)
+	}
+
+	// Checkout only offers active options, so the minimum must be reachable with them
+	activeOptions := 0
+	for _, option := range options {
+		if option.Active {
+			activeOptions++
+		}
+	}
+	if req.MinSelections > activeOptions {
+		return fmt.Errorf("%w: min_selections cannot exceed the number of active options (%d)", ErrInvalidModifierGroup, activeOptions)
+	}
+
+	group.Name = name
+	group.SelectionType = req.SelectionType
+	group.MinSelections = req.MinSelections
+	group.MaxSelections = maxSelections
+	if req.Active != nil {
+		group.Active = *req.Active
+	}
+	group.Options = options
+	return nil
+}
+
+// resolveModifiers validates the option IDs chosen for a menu item against the
+// modifier groups attached to it and returns the modifier snapshots together
+// with the total price delta
+// Every group must receive between MinSelections and MaxSelections options, so
+// groups with a minimum must be answered even when nothing was chosen from them
+func resolveModifiers(menu *model.Menu, groups []model.ModifierGroup, optionIDs []uint) ([]model.TransactionDetailModifier, money.Money, error) {
+	type choice struct {
+		group  *model.ModifierGroup
+		option *model.ModifierOption
+	}
+
+	available := make(map[uint]choice)
+	for i := range groups {
+		group := &groups[i]
+		for j := range group.Options {
+			available[group.Options[j].ID] = choice{group: group, option: &group.Options[j]}
+		}
+	}
+
+	selected := make(map[uint]bool, len(optionIDs))
+	counts := make(map[uint]int, len(groups))
+	modifiers := make([]model.TransactionDetailModifier, 0, len(optionIDs))
+	var delta money.Money
+
+	for _, optionID := range optionIDs {
+		chosen, ok := available[optionID]
+		if !ok {
+			return nil, 0, fmt.Errorf("%w: option %d is not available for '%s'", ErrInvalidModifierSelection, optionID, menu.Name)
+		}
+		if selected[optionID] {
+			return nil, 0, fmt.Errorf("%w: option '%s' was chosen more than once", ErrInvalidModifierSelection, chosen.option.Name)
+		}
+		selected[optionID] = true
+		counts[chosen.group.ID]++
+
+		modifiers = append(modifiers, model.TransactionDetailModifier{
+			ModifierGroupID:  chosen.group.ID,
+			ModifierOptionID: chosen.option.ID,
+			GroupName:        chosen.group.Name,
+			OptionName:       chosen.option.Name,
+			PriceDelta:       chosen.option.PriceDelta,
+		})
+		delta = delta.Add(chosen.option.PriceDelta)
+	}
+
+	for _, group := range groups {
+		count := counts[group.ID]
+		if len(group.Options) < group.MinSelections {
+			return nil, 0, fmt.Errorf("%w: '%s' requires %d option(s) of '%s' but only %d are active",
+				ErrInvalidModifierSelection, menu.Name, group.MinSelections, group.Name, len(group.Options))
+		}
+		if count < group.MinSelections {
+			return nil, 0, fmt.Errorf("%w: choose at least %d option(s) of '%s' for '%s'",
+				ErrInvalidModifierSelection, group.MinSelections, group.Name, menu.Name)
+		}
+		if group.MaxSelections > 0 && count > group.MaxSelections {
+			return nil, 0, fmt.Errorf("%w: choose at most %d option(s) of '%s' for '%s'",
+				ErrInvalidModifierSelection, group.MaxSelections, group.Name, menu.Name)
+		}
+	}
+
+	if menu.Price.Add(delta).IsNegative() {
+		return nil, 0, fmt.Errorf("%w: modifiers take the price of '%s' below zero", ErrInvalidModifierSelection, menu.Name)
+	}
+
+	return modifiers, delta, nil
+}
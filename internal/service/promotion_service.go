@@ -0,0 +1,198 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"service-cashier/internal/model"
+	"service-cashier/internal/repository"
+	"service-cashier/pkg/money"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrInvalidPromotion is returned when a promotion definition is inconsistent
+	ErrInvalidPromotion = errors.New("invalid promotion")
+	// ErrPromotionCodeExists is returned when another promotion already uses the code
+	ErrPromotionCodeExists = errors.New("promotion with this code already exists")
+	// ErrPromotionInUse is returned when deleting a promotion that was already redeemed
+	ErrPromotionInUse = errors.New("promotion has been redeemed; deactivate it instead")
+)
+
+// PromotionService handles promotion business logic
+type PromotionService struct {
+	promotionRepo *repository.PromotionRepository
+	menuRepo      *repository.MenuRepository
+}
+
+// NewPromotionService creates a new PromotionService instance
+func NewPromotionService(promotionRepo *repository.PromotionRepository, menuRepo *repository.MenuRepository) *PromotionService {
+	return &PromotionService{
+		promotionRepo: promotionRepo,
+		menuRepo:      menuRepo,
+	}
+}
+
+// PromotionRequest represents the create and update promotion payload
+// Percentage is used by percentage promotions and Amount by fixed ones;
+// MenuID is required for item-scoped and buy-X-get-Y promotions
+type PromotionRequest struct {
+	Code        string      `json:"code" binding:"required,max=50"`
+	Name        string      `json:"name" binding:"required,max=100"`
+	Type        string      `json:"type" binding:"required,oneof=percentage fixed buy_x_get_y"`
+	Scope       string      `json:"scope" binding:"omitempty,oneof=order item"`
+	Percentage  money.Rate  `json:"percentage" binding:"min=0,max=10000"`
+	Amount      money.Money `json:"amount" binding:"min=0,max=9999999999"`
+	MaxDiscount money.Money `json:"max_discount" binding:"min=0,max=9999999999"`
+	MinSubtotal money.Money `json:"min_subtotal" binding:"min=0,max=9999999999"`
+	MenuID      *uint       `json:"menu_id"`
+	BuyQty      int         `json:"buy_qty" binding:"min=0"`
+	GetQty      int         `json:"get_qty" binding:"min=0"`
+	StartsAt    *time.Time  `json:"starts_at"`
+	EndsAt      *time.Time  `json:"ends_at"`
+	UsageLimit  int         `json:"usage_limit" binding:"min=0"`
+	Active      *bool       `json:"active"`
+}
+
+// GetAllPromotions retrieves all promotions
+func (s *PromotionService) GetAllPromotions() ([]model.Promotion, error) {
+	return s.promotionRepo.GetAll()
+}
+
+// GetPromotionByID retrieves a promotion by ID
+func (s *PromotionService) GetPromotionByID(id uint) (*model.Promotion, error) {
+	promotion, err := s.promotionRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPromotionNotFound
+		}
+		return nil, err
+	}
+	return promotion, nil
+}
+
+// CreatePromotion creates a new promotion
+func (s *PromotionService) CreatePromotion(req *PromotionRequest) (*model.Promotion, error) {
+	promotion := &model.Promotion{Active: true}
+	if err := s.apply(promotion, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.promotionRepo.Create(promotion); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrPromotionCodeExists
+		}
+		return nil, err
+	}
+	return promotion, nil
+}
+
+// UpdatePromotion replaces the definition of an existing promotion
+// The usage count is preserved
+func (s *PromotionService) UpdatePromotion(id uint, req *PromotionRequest) (*model.Promotion, error) {
+	promotion, err := s.GetPromotionByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.apply(promotion, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.promotionRepo.Update(promotion); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrPromotionCodeExists
+		}
+		return nil, err
+	}
+	return promotion, nil
+}
+
+// DeletePromotion deletes a promotion that has never been redeemed
+func (s *PromotionService) DeletePromotion(id uint) error {
+	if _, err := s.GetPromotionByID(id); err != nil {
+		return err
+	}
+
+	redeemed, err := s.promotionRepo.IsRedeemed(id)
+	if err != nil {
+		return err
+	}
+	if redeemed {
+		return ErrPromotionInUse
+	}
+
+	return s.promotionRepo.Delete(id)
+}
+
+// apply validates the request and copies it onto the promotion
+func (s *PromotionService) apply(promotion *model.Promotion, req *PromotionRequest) error {
+	scope := req.Scope
+	if scope == "" {
+		scope = model.PromotionScopeOrder
+	}
+	if req.Type == model.PromotionTypeBuyXGetY {
+		scope = model.PromotionScopeItem
+	}
+
+	switch req.Type {
+	case model.PromotionTypePercentage:
+		if req.Percentage.IsZero() {
+			return fmt.Errorf("%w: percentage must be greater than 0", ErrInvalidPromotion)
+		}
+	case model.PromotionTypeFixed:
+		if req.Amount.IsZero() {
+			return fmt.Errorf("%w: amount must be greater than 0", ErrInvalidPromotion)
+		}
+	case model.PromotionTypeBuyXGetY:
+		if req.BuyQty < 1 || req.GetQty < 1 {
+			return fmt.Errorf("%w: buy_qty and get_qty must be at least 1", ErrInvalidPromotion)
+		}
+	}
+
+	if scope == model.PromotionScopeItem {
+		if req.MenuID == nil {
+			return fmt.Errorf("%w: menu_id is required for item promotions", ErrInvalidPromotion)
+		}
+		if _, err := s.menuRepo.FindByID(*req.MenuID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("%w: menu item %d not found", ErrInvalidPromotion, *req.MenuID)
+			}
+			return err
+		}
+	}
+
+	if req.StartsAt != nil && req.EndsAt != nil && !req.EndsAt.After(*req.StartsAt) {
+		return fmt.Errorf("%w: ends_at must be after starts_at", ErrInvalidPromotion)
+	}
+
+	code := strings.ToUpper(strings.TrimSpace(req.Code))
+	name := strings.TrimSpace(req.Name)
+	if code == "" || name == "" {
+		return fmt.Errorf("%w: code and name are required", ErrInvalidPromotion)
+	}
+
+	promotion.Code = code
+	promotion.Name = name
+	promotion.Type = req.Type
+	promotion.Scope = scope
+	promotion.Percentage = req.Percentage
+	promotion.Amount = req.Amount
+	promotion.MaxDiscount = req.MaxDiscount
+	promotion.MinSubtotal = req.MinSubtotal
+	promotion.MenuID = nil
+	if scope == model.PromotionScopeItem {
+		promotion.MenuID = req.MenuID
+	}
+	promotion.BuyQty = req.BuyQty
+	promotion.GetQty = req.GetQty
+	promotion.StartsAt = req.StartsAt
+	promotion.EndsAt = req.EndsAt
+	promotion.UsageLimit = req.UsageLimit
+	if req.Active != nil {
+		promotion.Active = *req.Active
+	}
+	return nil
+}
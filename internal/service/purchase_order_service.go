@@ -0,0 +1,533 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"service-cashier/internal/model"
+	"service-cashier/internal/repository"
+	"service-cashier/pkg/money"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrPurchaseOrderNotFound is returned when the requested purchase order does not exist
+	ErrPurchaseOrderNotFound = errors.New("purchase order not found")
+	// ErrInvalidPurchaseOrder is returned when purchase order lines or receipts are inconsistent
+	ErrInvalidPurchaseOrder = errors.New("invalid purchase order")
+	// ErrPurchaseOrderStatus is returned when an action is not allowed in the current purchase order status
+	ErrPurchaseOrderStatus = errors.New("action not allowed in the current purchase order status")
+)
+
+// PurchaseOrderService handles purchase orders and receiving against them
+type PurchaseOrderService struct {
+	purchaseOrderRepo *repository.PurchaseOrderRepository
+	supplierRepo      *repository.SupplierRepository
+	menuRepo          *repository.MenuRepository
+	ingredientRepo    *repository.IngredientRepository
+}
+
+// NewPurchaseOrderService creates a new PurchaseOrderService instance
+func NewPurchaseOrderService(purchaseOrderRepo *repository.PurchaseOrderRepository, supplierRepo *repository.SupplierRepository, menuRepo *repository.MenuRepository, ingredientRepo *repository.IngredientRepository) *PurchaseOrderService {
+	return &PurchaseOrderService{
+		purchaseOrderRepo: purchaseOrderRepo,
+		supplierRepo:      supplierRepo,
+		menuRepo:          menuRepo,
+		ingredientRepo:    ingredientRepo,
+	}
+}
+
+// PurchaseOrderLineRequest represents one ordered item of a purchase order payload
+// Exactly one of MenuID and IngredientID must be set. ExpectedCost is the cost
+// of the whole quantity
+type PurchaseOrderLineRequest struct {
+	MenuID       *uint       `json:"menu_id"`
+	IngredientID *uint       `json:"ingredient_id"`
+	Quantity     int         `json:"quantity" binding:"required,min=1"`
+	ExpectedCost money.Money `json:"expected_cost" binding:"min=0,max=9999999999"`
+}
+
+// PurchaseOrderRequest represents the create and update purchase order payload
+type PurchaseOrderRequest struct {
+	SupplierID uint                       `json:"supplier_id" binding:"required"`
+	Note       string                     `json:"note" binding:"max=255"`
+	Lines      []PurchaseOrderLineRequest `json:"lines" binding:"required,min=1,dive"`
+}
+
+// PurchaseOrderListQuery represents the query parameters of the purchase order listing
+type PurchaseOrderListQuery struct {
+	Status     string `form:"status" binding:"omitempty,oneof=draft sent partially_received received closed"`
+	SupplierID uint   `form:"supplier_id"`
+}
+
+// ReceivePurchaseOrderLine represents the delivered quantity of one purchase order line
+// Cost is what the delivered quantity cost as a whole and defaults to its share
+// of the line's expected cost
+type ReceivePurchaseOrderLine struct {
+	LineID   uint         `json:"line_id" binding:"required"`
+	Quantity int          `json:"quantity" binding:"required,min=1"`
+	Cost     *money.Money `json:"cost" binding:"omitempty,min=0,max=9999999999"`
+}
+
+// ReceivePurchaseOrderRequest represents a delivery received against a purchase order
+// ReferenceNumber is the supplier's delivery note or invoice number
+type ReceivePurchaseOrderRequest struct {
+	Lines           []ReceivePurchaseOrderLine `json:"lines" binding:"required,min=1,dive"`
+	ReferenceNumber string                     `json:"reference_number" binding:"max=100"`
+	Reason          string                     `json:"reason" binding:"max=255"`
+}
+
+// GetPurchaseOrders retrieves purchase orders newest first
+func (s *PurchaseOrderService) GetPurchaseOrders(query *PurchaseOrderListQuery) ([]model.PurchaseOrder, error) {
+	return s.purchaseOrderRepo.GetAll(repository.PurchaseOrderFilter{
+		Status:     query.Status,
+		SupplierID: query.SupplierID,
+	})
+}
+
+// GetPurchaseOrderByID retrieves a purchase order with its supplier and lines
+func (s *PurchaseOrderService) GetPurchaseOrderByID(id uint) (*model.PurchaseOrder, error) {
+	order, err := s.purchaseOrderRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPurchaseOrderNotFound
+		}
+		return nil, err
+	}
+	return order, nil
+}
+
+// CreatePurchaseOrder creates a draft purchase order
+func (s *PurchaseOrderService) CreatePurchaseOrder(actorID uint, req *PurchaseOrderRequest) (*model.PurchaseOrder, error) {
+	if err := s.ensureActiveSupplier(req.SupplierID); err != nil {
+		return nil, err
+	}
+
+	lines, total, err := s.buildLines(req.Lines)
+	if err != nil {
+		return nil, err
+	}
+
+	order := &model.PurchaseOrder{
+		SupplierID:    req.SupplierID,
+		Status:        model.PurchaseOrderDraft,
+		Note:          strings.TrimSpace(req.Note),
+		ExpectedTotal: total,
+		CreatedByID:   actorID,
+		Lines:         lines,
+	}
+
+	tx := s.purchaseOrderRepo.BeginTransaction()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := s.purchaseOrderRepo.Create(tx, order); err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to create purchase order: %w", err)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return s.GetPurchaseOrderByID(order.ID)
+}
+
+// UpdatePurchaseOrder replaces the supplier, note and lines of a draft purchase order
+func (s *PurchaseOrderService) UpdatePurchaseOrder(id uint, req *PurchaseOrderRequest) (*model.PurchaseOrder, error) {
+	if err := s.ensureActiveSupplier(req.SupplierID); err != nil {
+		return nil, err
+	}
+
+	lines, total, err := s.buildLines(req.Lines)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := s.purchaseOrderRepo.BeginTransaction()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	order, err := s.lockPurchaseOrder(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if order.Status != model.PurchaseOrderDraft {
+		tx.Rollback()
+		return nil, fmt.Errorf("%w: only draft purchase orders can be edited", ErrPurchaseOrderStatus)
+	}
+
+	order.SupplierID = req.SupplierID
+	order.Note = strings.TrimSpace(req.Note)
+	order.ExpectedTotal = total
+	if err := s.purchaseOrderRepo.Update(tx, order); err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to update purchase order: %w", err)
+	}
+	if err := s.purchaseOrderRepo.ReplaceLines(tx, order.ID, lines); err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to update purchase order lines: %w", err)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return s.GetPurchaseOrderByID(id)
+}
+
+// DeletePurchaseOrder deletes a draft purchase order
+func (s *PurchaseOrderService) DeletePurchaseOrder(id uint) error {
+	tx := s.purchaseOrderRepo.BeginTransaction()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	order, err := s.lockPurchaseOrder(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if order.Status != model.PurchaseOrderDraft {
+		tx.Rollback()
+		return fmt.Errorf("%w: only draft purchase orders can be deleted; close it instead", ErrPurchaseOrderStatus)
+	}
+
+	if err := s.purchaseOrderRepo.Delete(tx, id); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to delete purchase order: %w", err)
+	}
+
+	return tx.Commit().Error
+}
+
+// SendPurchaseOrder marks a draft purchase order as sent to the supplier, after
+// which its lines can no longer change
+func (s *PurchaseOrderService) SendPurchaseOrder(id uint) (*model.PurchaseOrder, error) {
+	return s.transition(id, func(order *model.PurchaseOrder, now time.Time) error {
+		if order.Status != model.PurchaseOrderDraft {
+			return fmt.Errorf("%w: only draft purchase orders can be sent", ErrPurchaseOrderStatus)
+		}
+		order.Status = model.PurchaseOrderSent
+		order.SentAt = &now
+		return nil
+	})
+}
+
+// ClosePurchaseOrder closes a sent purchase order
+// Closing a partially received order writes off the quantities still outstanding
+func (s *PurchaseOrderService) ClosePurchaseOrder(id uint) (*model.PurchaseOrder, error) {
+	return s.transition(id, func(order *model.PurchaseOrder, now time.Time) error {
+		if order.Status == model.PurchaseOrderDraft || order.Status == model.PurchaseOrderClosed {
+			return fmt.Errorf("%w: only sent or received purchase orders can be closed", ErrPurchaseOrderStatus)
+		}
+		order.Status = model.PurchaseOrderClosed
+		order.ClosedAt = &now
+		return nil
+	})
+}
+
+// ReceivePurchaseOrder adds a delivery against a sent purchase order to stock
+// Each received line is posted as a restock movement through the same locked
+// update as the manual receive endpoints, carrying its cost, the supplier and
+// the reference number, so menu cost prices and ingredient stock values move to
+// the new weighted average. Menu items are locked before ingredients, each in ID order,
+// like checkout does
+func (s *PurchaseOrderService) ReceivePurchaseOrder(actorID, id uint, req *ReceivePurchaseOrderRequest) (*model.PurchaseOrder, error) {
+	tx := s.purchaseOrderRepo.BeginTransaction()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	order, err := s.lockPurchaseOrder(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if !order.IsReceivable() {
+		tx.Rollback()
+		return nil, fmt.Errorf("%w: only sent purchase orders can be received", ErrPurchaseOrderStatus)
+	}
+
+	supplier, err := s.supplierRepo.FindByID(order.SupplierID)
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to fetch supplier: %w", err)
+	}
+
+	receipts, err := matchReceipts(order, req.Lines)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		reason = fmt.Sprintf("Purchase order #%d", order.ID)
+	}
+	reference := strings.TrimSpace(req.ReferenceNumber)
+
+	for _, receipt := range receipts {
+		line := receipt.line
+		cost := line.ExpectedCost.Prorate(receipt.quantity, line.Quantity)
+		if receipt.cost != nil {
+			cost = *receipt.cost
+		}
+
+		if line.MenuID != nil {
+			menu, err := s.menuRepo.FindByIDWithLockUnscoped(tx, *line.MenuID)
+			if err != nil {
+				tx.Rollback()
+				return nil, fmt.Errorf("failed to fetch menu item %d: %w", *line.MenuID, err)
+			}
+			unitCost := cost.Prorate(1, receipt.quantity)
+			movement := &model.StockMovement{
+				Type:            model.StockMovementRestock,
+				Quantity:        receipt.quantity,
+				UserID:          &actorID,
+				Reason:          reason,
+				UnitCost:        &unitCost,
+				Supplier:        supplier.Name,
+				ReferenceNumber: reference,
+				PurchaseOrderID: &order.ID,
+			}
+			if err := s.menuRepo.ApplyStockMovement(tx, menu, movement); err != nil {
+				tx.Rollback()
+				return nil, fmt.Errorf("failed to update stock: %w", err)
+			}
+		} else {
+			ingredient, err := s.ingredientRepo.FindByIDWithLock(tx, *line.IngredientID)
+			if err != nil {
+				tx.Rollback()
+				return nil, fmt.Errorf("failed to fetch ingredient %d: %w", *line.IngredientID, err)
+			}
+			movement := &model.IngredientMovement{
+				Type:            model.StockMovementRestock,
+				Quantity:        receipt.quantity,
+				UserID:          &actorID,
+				Reason:          reason,
+				TotalCost:       &cost,
+				Supplier:        supplier.Name,
+				ReferenceNumber: reference,
+				PurchaseOrderID: &order.ID,
+			}
+			if err := s.ingredientRepo.ApplyMovement(tx, ingredient, movement); err != nil {
+				tx.Rollback()
+				return nil, fmt.Errorf("failed to update ingredient stock: %w", err)
+			}
+		}
+
+		line.ReceivedQty += receipt.quantity
+		line.ReceivedCost = line.ReceivedCost.Add(cost)
+		order.ReceivedTotal = order.ReceivedTotal.Add(cost)
+		if err := s.purchaseOrderRepo.UpdateLine(tx, line); err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("failed to update purchase order line: %w", err)
+		}
+	}
+
+	order.Status = model.PurchaseOrderReceived
+	for i := range order.Lines {
+		if order.Lines[i].Outstanding() > 0 {
+			order.Status = model.PurchaseOrderPartiallyReceived
+			break
+		}
+	}
+	if order.Status == model.PurchaseOrderReceived {
+		now := time.Now()
+		order.ReceivedAt = &now
+	}
+	if err := s.purchaseOrderRepo.Update(tx, order); err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to update purchase order: %w", err)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return s.GetPurchaseOrderByID(id)
+}
+
+// transition applies a status change to a purchase order under a row lock
+func (s *PurchaseOrderService) transition(id uint, apply func(order *model.PurchaseOrder, now time.Time) error) (*model.PurchaseOrder, error) {
+	tx := s.purchaseOrderRepo.BeginTransaction()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	order, err := s.lockPurchaseOrder(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := apply(order, time.Now()); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := s.purchaseOrderRepo.Update(tx, order); err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to update purchase order: %w", err)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return s.GetPurchaseOrderByID(id)
+}
+
+// lockPurchaseOrder locks a purchase order and its lines
+func (s *PurchaseOrderService) lockPurchaseOrder(tx *gorm.DB, id uint) (*model.PurchaseOrder, error) {
+	order, err := s.purchaseOrderRepo.FindByIDWithLock(tx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPurchaseOrderNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch purchase order: %w", err)
+	}
+	return order, nil
+}
+
+// ensureActiveSupplier checks that the supplier exists and takes new orders
+func (s *PurchaseOrderService) ensureActiveSupplier(id uint) error {
+	supplier, err := s.supplierRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrSupplierNotFound
+		}
+		return err
+	}
+	if !supplier.Active {
+		return ErrSupplierInactive
+	}
+	return nil
+}
+
+// buildLines validates the requested lines and resolves the name of each item
+// Menu items made from a recipe are rejected, since their stock lives in their
+// ingredients. It returns the lines and their expected total
+func (s *PurchaseOrderService) buildLines(reqs []PurchaseOrderLineRequest) ([]model.PurchaseOrderLine, money.Money, error) {
+	var total money.Money
+	lines := make([]model.PurchaseOrderLine, 0, len(reqs))
+	seenMenus := make(map[uint]bool)
+	seenIngredients := make(map[uint]bool)
+
+	for _, req := range reqs {
+		line := model.PurchaseOrderLine{
+			Quantity:     req.Quantity,
+			ExpectedCost: req.ExpectedCost,
+		}
+
+		switch {
+		case req.MenuID != nil && req.IngredientID == nil:
+			if seenMenus[*req.MenuID] {
+				return nil, 0, fmt.Errorf("%w: menu item %d is listed more than once", ErrInvalidPurchaseOrder, *req.MenuID)
+			}
+			seenMenus[*req.MenuID] = true
+
+			menu, err := s.menuRepo.FindByID(*req.MenuID)
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return nil, 0, fmt.Errorf("%w: menu item %d does not exist", ErrInvalidPurchaseOrder, *req.MenuID)
+				}
+				return nil, 0, err
+			}
+			if menu.HasRecipe() {
+				return nil, 0, fmt.Errorf("%w: '%s' is made from a recipe; order its ingredients instead", ErrInvalidPurchaseOrder, menu.Name)
+			}
+			line.MenuID = &menu.ID
+			line.Name = menu.Name
+
+		case req.IngredientID != nil && req.MenuID == nil:
+			if seenIngredients[*req.IngredientID] {
+				return nil, 0, fmt.Errorf("%w: ingredient %d is listed more than once", ErrInvalidPurchaseOrder, *req.IngredientID)
+			}
+			seenIngredients[*req.IngredientID] = true
+
+			ingredient, err := s.ingredientRepo.FindByID(*req.IngredientID)
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return nil, 0, fmt.Errorf("%w: ingredient %d does not exist", ErrInvalidPurchaseOrder, *req.IngredientID)
+				}
+				return nil, 0, err
+			}
+			line.IngredientID = &ingredient.ID
+			line.Name = ingredient.Name
+
+		default:
+			return nil, 0, fmt.Errorf("%w: each line needs either a menu_id or an ingredient_id", ErrInvalidPurchaseOrder)
+		}
+
+		total = total.Add(line.ExpectedCost)
+		lines = append(lines, line)
+	}
+
+	return lines, total, nil
+}
+
+// purchaseOrderReceipt is a validated delivery of one purchase order line
+type purchaseOrderReceipt struct {
+	line     *model.PurchaseOrderLine
+	quantity int
+	cost     *money.Money
+}
+
+// matchReceipts pairs the received lines with the lines of the order and checks
+// that no line receives more than is outstanding
+// Receipts are ordered with menu items first and then ingredients, each by ID,
+// which is the order their rows are locked in
+func matchReceipts(order *model.PurchaseOrder, reqs []ReceivePurchaseOrderLine) ([]purchaseOrderReceipt, error) {
+	lines := make(map[uint]*model.PurchaseOrderLine, len(order.Lines))
+	for i := range order.Lines {
+		lines[order.Lines[i].ID] = &order.Lines[i]
+	}
+
+	receipts := make([]purchaseOrderReceipt, 0, len(reqs))
+	seen := make(map[uint]bool, len(reqs))
+	for _, req := range reqs {
+		line := lines[req.LineID]
+		if line == nil {
+			return nil, fmt.Errorf("%w: line %d is not part of this purchase order", ErrInvalidPurchaseOrder, req.LineID)
+		}
+		if seen[req.LineID] {
+			return nil, fmt.Errorf("%w: line %d is listed more than once", ErrInvalidPurchaseOrder, req.LineID)
+		}
+		seen[req.LineID] = true
+
+		if req.Quantity > line.Outstanding() {
+			return nil, fmt.Errorf("%w: '%s' has %d outstanding, received %d", ErrInvalidPurchaseOrder, line.Name, line.Outstanding(), req.Quantity)
+		}
+		receipts = append(receipts, purchaseOrderReceipt{line: line, quantity: req.Quantity, cost: req.Cost})
+	}
+
+	sort.Slice(receipts, func(i, j int) bool {
+		a, b := receipts[i].line, receipts[j].line
+		if (a.MenuID != nil) != (b.MenuID != nil) {
+			return a.MenuID != nil
+		}
+		if a.MenuID != nil {
+			return *a.MenuID < *b.MenuID
+		}
+		return *a.IngredientID < *b.IngredientID
+	})
+	return receipts, nil
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"service-cashier/pkg/money"
+	"time"
+)
+
+// parseQueryTimeRange parses the from and to query parameters of a listing
+// Both accept a date (2006-01-02) or an RFC 3339 timestamp. Dates are interpreted in
+// the server's time zone, and a date in to includes the whole day, so the returned
+// upper bound is exclusive
+func parseQueryTimeRange(fromValue, toValue string) (from, to *time.Time, err error) {
+	if from, err = parseQueryTime("from", fromValue, false); err != nil {
+		return nil, nil, err
+	}
+	if to, err = parseQueryTime("to", toValue, true); err != nil {
+		return nil, nil, err
+	}
+	if from != nil && to != nil && !from.Before(*to) {
+		return nil, nil, errors.New("from must be before to")
+	}
+	return from, to, nil
+}
+
+// parseQueryTime parses a date or RFC 3339 timestamp query parameter, returning nil when empty
+// With endOfDay a date refers to the start of the following day
+func parseQueryTime(name, value string, endOfDay bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+
+	t, err := time.ParseInLocation(time.DateOnly, value, time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be a date (YYYY-MM-DD) or RFC 3339 timestamp", name)
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
+
+// parseQueryAmount parses an amount query parameter, returning nil when it is empty
+func parseQueryAmount(name, value string) (*money.Money, error) {
+	if value == "" {
+		return nil, nil
+	}
+	amount, err := money.Parse(value)
+	if err != nil || amount.IsNegative() {
+		return nil, fmt.Errorf("%s must be a non-negative amount", name)
+	}
+	return &amount, nil
+}
@@ -0,0 +1,374 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"service-cashier/internal/model"
+	"service-cashier/internal/repository"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrTransactionNotFound is returned when the requested transaction does not exist
+	ErrTransactionNotFound = errors.New("transaction not found")
+	// ErrNothingToRefund is returned when every item of the transaction was already refunded
+	ErrNothingToRefund = errors.New("transaction has no remaining items to refund")
+	// ErrInvalidRefundItem is returned when a refund line does not match the transaction
+	ErrInvalidRefundItem = errors.New("invalid refund item")
+	// ErrRefundReasonRequired is returned when a refund is requested without a reason
+	ErrRefundReasonRequired = errors.New("refund reason is required")
+)
+
+// RefundService handles voids and refunds of completed transactions
+type RefundService struct {
+	refundRepo      *repository.RefundRepository
+	transactionRepo *repository.TransactionRepository
+	menuRepo        *repository.MenuRepository
+	ingredientRepo  *repository.IngredientRepository
+	promotionRepo   *repository.PromotionRepository
+}
+
+// NewRefundService creates a new RefundService instance
+func NewRefundService(refundRepo *repository.RefundRepository, transactionRepo *repository.TransactionRepository, menuRepo *repository.MenuRepository, ingredientRepo *repository.IngredientRepository, promotionRepo *repository.PromotionRepository) *RefundService {
+	return &RefundService{
+		refundRepo:      refundRepo,
+		transactionRepo: transactionRepo,
+		menuRepo:        menuRepo,
+		ingredientRepo:  ingredientRepo,
+		promotionRepo:   promotionRepo,
+	}
+}
+
+// RefundItemRequest represents a single transaction detail to refund
+type RefundItemRequest struct {
+	DetailID uint `json:"detail_id" binding:"required"`
+	Qty      int  `json:"qty" binding:"required,min=1"`
+}
+
+// RefundRequest represents the void and refund request payload
+// When Items is empty, every remaining item of the transaction is refunded
+type RefundRequest struct {
+	Reason string              `json:"reason" binding:"required,max=255"`
+	Items  []RefundItemRequest `json:"items" binding:"omitempty,dive"`
+}
+
+// VoidTransaction voids some or all items of a transaction and restores their stock
+func (s *RefundService) VoidTransaction(transactionID, approverID uint, req *RefundRequest) (*model.Refund, error) {
+	return s.createRefund(transactionID, approverID, model.RefundTypeVoid, req)
+}
+
+// RefundTransaction refunds some or all items of a transaction and restores their stock
+func (s *RefundService) RefundTransaction(transactionID, approverID uint, req *RefundRequest) (*model.Refund, error) {
+	return s.createRefund(transactionID, approverID, model.RefundTypeRefund, req)
+}
+
+// GetRefundsByTransaction retrieves all voids and refunds of a transaction
+func (s *RefundService) GetRefundsByTransaction(transactionID uint) ([]model.Refund, error) {
+	if _, err := s.transactionRepo.FindByID(transactionID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTransactionNotFound
+		}
+		return nil, err
+	}
+	return s.refundRepo.GetByTransactionID(transactionID)
+}
+
+// createRefund records a refund document and returns stock within a single database transaction
+// The transaction and its details are locked with FindByIDWithLock and every menu row
+// with MenuRepository.FindByIDWithLockUnscoped, following the same pattern as Checkout
+func (s *RefundService) createRefund(transactionID, approverID uint, refundType string, req *RefundRequest) (*model.Refund, error) {
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, ErrRefundReasonRequired
+	}
+
+	// Start a database transaction
+	tx := s.transactionRepo.BeginTransaction()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	transaction, err := s.transactionRepo.FindByIDWithLock(tx, transactionID)
+	if err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTransactionNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch transaction: %w", err)
+	}
+
+	quantities, err := resolveRefundQuantities(transaction, req.Items)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	refund := &model.Refund{
+		TransactionID: transaction.ID,
+		Type:          refundType,
+		Reason:        reason,
+		ApprovedByID:  approverID,
+	}
+
+	for i := range transaction.Details {
+		detail := &transaction.Details[i]
+		qty := quantities[detail.ID]
+		if qty == 0 {
+			continue
+		}
+
+		// Refunds include the line's share of service charge and tax. Refunding the
+		// last remaining units returns whatever is left of the line, so rounding
+		// never leaves a cent behind
+		amount := detail.LineTotal.Prorate(qty, detail.Qty)
+		if qty == detail.RemainingQty() {
+			amount = detail.LineTotal.Sub(detail.RefundedAmount)
+		}
+
+		detail.RefundedQty += qty
+		detail.RefundedAmount = detail.RefundedAmount.Add(amount)
+		if err := s.transactionRepo.UpdateDetailRefundState(tx, detail); err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("failed to update transaction detail: %w", err)
+		}
+
+		refund.Items = append(refund.Items, model.RefundItem{
+			TransactionDetailID: detail.ID,
+			MenuID:              detail.MenuID,
+			MenuName:            detail.MenuName,
+			Qty:                 qty,
+			Amount:              amount,
+		})
+		refund.TotalAmount = refund.TotalAmount.Add(amount)
+	}
+
+	// The status depends on every earlier void and refund, which the transaction
+	// row lock keeps stable until this one is recorded
+	history, err := s.refundRepo.GetTypesByTransactionID(tx, transaction.ID)
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to fetch refund history: %w", err)
+	}
+
+	transaction.RefundedAmount = transaction.RefundedAmount.Add(refund.TotalAmount)
+	transaction.Status = refundStatus(transaction, append(history, refundType))
+	if err := s.transactionRepo.UpdateRefundState(tx, transaction); err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to update transaction: %w", err)
+	}
+
+	if err := s.refundRepo.Create(tx, refund); err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to create refund: %w", err)
+	}
+
+	// Return stock in menu ID order to keep lock acquisition consistent
+	if err := s.restoreStock(tx, transaction, refund, approverID); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// A voided sale never happened, so it gives back its promo code uses
+	if transaction.Status == model.TransactionStatusVoided {
+		if err := s.releasePromotions(tx, transaction.ID); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	// Commit the transaction
+	if err := tx.Commit().Error; err != nil {
+		return nil, fmt.Errorf("failed to commit refund: %w", err)
+	}
+
+	return refund, nil
+}
+
+// releasePromotions decrements the usage count of every promotion the transaction
+// redeemed, under the promotion row locks taken in ID order
+// Promotions deleted since are skipped
+func (s *RefundService) releasePromotions(tx *gorm.DB, transactionID uint) error {
+	promotionIDs, err := s.transactionRepo.GetPromotionIDs(tx, transactionID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch redeemed promotions: %w", err)
+	}
+
+	for _, id := range promotionIDs {
+		if _, err := s.promotionRepo.FindByIDWithLock(tx, id); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			return fmt.Errorf("failed to fetch promotion: %w", err)
+		}
+		if err := s.promotionRepo.DecrementUsage(tx, id); err != nil {
+			return fmt.Errorf("failed to update promotion usage: %w", err)
+		}
+	}
+	return nil
+}
+
+// restoreStock adds refunded quantities back to menu stock under row locks
+// and records a refund or void movement per menu item
+// Lines sold from a recipe consumed ingredients rather than menu stock. A void
+// returns the ingredients the voided units used; a refund does not, since the
+// item was already prepared
+func (s *RefundService) restoreStock(tx *gorm.DB, transaction *model.Transaction, refund *model.Refund, approverID uint) error {
+	movementType := model.StockMovementRefund
+	if refund.Type == model.RefundTypeVoid {
+		movementType = model.StockMovementVoid
+	}
+
+	detailIDs := make([]uint, len(refund.Items))
+	for i, item := range refund.Items {
+		detailIDs[i] = item.TransactionDetailID
+	}
+	usage, err := s.ingredientRepo.GetSaleMovements(tx, detailIDs)
+	if err != nil {
+		return fmt.Errorf("failed to fetch ingredient usage: %w", err)
+	}
+	fromRecipe := make(map[uint]bool, len(usage))
+	for _, movement := range usage {
+		fromRecipe[*movement.TransactionDetailID] = true
+	}
+
+	totals := make(map[uint]int)
+	var menuIDs []uint
+	for _, item := range refund.Items {
+		if fromRecipe[item.TransactionDetailID] {
+			continue
+		}
+		if _, seen := totals[item.MenuID]; !seen {
+			menuIDs = append(menuIDs, item.MenuID)
+		}
+		totals[item.MenuID] += item.Qty
+	}
+	sort.Slice(menuIDs, func(i, j int) bool { return menuIDs[i] < menuIDs[j] })
+
+	for _, menuID := range menuIDs {
+		// Items archived since the sale still get their stock back
+		menu, err := s.menuRepo.FindByIDWithLockUnscoped(tx, menuID)
+		if err != nil {
+			return fmt.Errorf("failed to fetch menu item %d: %w", menuID, err)
+		}
+
+		movement := &model.StockMovement{
+			Type:          movementType,
+			Quantity:      totals[menuID],
+			UserID:        &approverID,
+			Reason:        refund.Reason,
+			TransactionID: &refund.TransactionID,
+			RefundID:      &refund.ID,
+		}
+		if err := s.menuRepo.ApplyStockMovement(tx, menu, movement); err != nil {
+			return fmt.Errorf("failed to update stock: %w", err)
+		}
+	}
+
+	if refund.Type == model.RefundTypeVoid {
+		return s.restoreIngredients(tx, transaction, refund, usage, approverID)
+	}
+	return nil
+}
+
+// restoreIngredients returns the ingredients used by the voided units under row
+// locks, in ingredient ID order like checkout
+func (s *RefundService) restoreIngredients(tx *gorm.DB, transaction *model.Transaction, refund *model.Refund, usage []model.IngredientMovement, approverID uint) error {
+	soldQty := make(map[uint]int, len(transaction.Details))
+	for _, detail := range transaction.Details {
+		soldQty[detail.ID] = detail.Qty
+	}
+	voidedQty := make(map[uint]int, len(refund.Items))
+	for _, item := range refund.Items {
+		voidedQty[item.TransactionDetailID] += item.Qty
+	}
+
+	// Sales record the usage of the whole line, which is a whole multiple of its quantity
+	returned := make(ingredientUsage)
+	for _, movement := range usage {
+		detailID := *movement.TransactionDetailID
+		returned.add(movement.IngredientID, -movement.Quantity/soldQty[detailID], voidedQty[detailID])
+	}
+
+	ingredients, err := s.ingredientRepo.FindByIDsWithLock(tx, returned.ids())
+	if err != nil {
+		return fmt.Errorf("failed to fetch ingredients: %w", err)
+	}
+
+	for i := range ingredients {
+		ingredient := &ingredients[i]
+		movement := &model.IngredientMovement{
+			Type:          model.StockMovementVoid,
+			Quantity:      returned[ingredient.ID],
+			UserID:        &approverID,
+			Reason:        refund.Reason,
+			TransactionID: &refund.TransactionID,
+			RefundID:      &refund.ID,
+		}
+		if err := s.ingredientRepo.ApplyMovement(tx, ingredient, movement); err != nil {
+			return fmt.Errorf("failed to update ingredient stock: %w", err)
+		}
+	}
+	return nil
+}
+
+// resolveRefundQuantities validates the requested lines against the transaction
+// and returns the quantity to refund per transaction detail ID
+func resolveRefundQuantities(transaction *model.Transaction, items []RefundItemRequest) (map[uint]int, error) {
+	quantities := make(map[uint]int)
+
+	// No explicit lines means a full refund of whatever remains
+	if len(items) == 0 {
+		for _, detail := range transaction.Details {
+			if remaining := detail.RemainingQty(); remaining > 0 {
+				quantities[detail.ID] = remaining
+			}
+		}
+		if len(quantities) == 0 {
+			return nil, ErrNothingToRefund
+		}
+		return quantities, nil
+	}
+
+	details := make(map[uint]*model.TransactionDetail, len(transaction.Details))
+	for i := range transaction.Details {
+		details[transaction.Details[i].ID] = &transaction.Details[i]
+	}
+
+	for _, item := range items {
+		detail, ok := details[item.DetailID]
+		if !ok {
+			return nil, fmt.Errorf("%w: detail %d does not belong to transaction %d", ErrInvalidRefundItem, item.DetailID, transaction.ID)
+		}
+
+		quantities[item.DetailID] += item.Qty
+		if quantities[item.DetailID] > detail.RemainingQty() {
+			return nil, fmt.Errorf("%w: cannot refund %d of '%s' (remaining: %d)",
+				ErrInvalidRefundItem, quantities[item.DetailID], detail.MenuName, detail.RemainingQty())
+		}
+	}
+	return quantities, nil
+}
+
+// refundStatus derives the transaction status after a refund has been applied
+// from the types of all its voids and refunds, including the new one
+// A fully returned sale is voided only when every operation was a void; once
+// any money was refunded the sale did happen and stays refunded
+func refundStatus(transaction *model.Transaction, refundTypes []string) string {
+	for _, detail := range transaction.Details {
+		if detail.RemainingQty() > 0 {
+			return model.TransactionStatusPartiallyRefunded
+		}
+	}
+
+	for _, refundType := range refundTypes {
+		if refundType != model.RefundTypeVoid {
+			return model.TransactionStatusRefunded
+		}
+	}
+	return model.TransactionStatusVoided
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"service-cashier/internal/model"
+	"testing"
+)
+
+func TestRefundStatus(t *testing.T) {
+	void, refund := model.RefundTypeVoid, model.RefundTypeRefund
+
+	tests := []struct {
+		name        string
+		refundedQty []int
+		refundTypes []string
+		want        string
+	}{
+		{name: "partial void", refundedQty: []int{1, 0}, refundTypes: []string{void}, want: model.TransactionStatusPartiallyRefunded},
+		{name: "partial refund", refundedQty: []int{2, 0}, refundTypes: []string{refund}, want: model.TransactionStatusPartiallyRefunded},
+		{name: "full void", refundedQty: []int{2, 1}, refundTypes: []string{void}, want: model.TransactionStatusVoided},
+		{name: "void in steps", refundedQty: []int{2, 1}, refundTypes: []string{void, void}, want: model.TransactionStatusVoided},
+		{name: "full refund", refundedQty: []int{2, 1}, refundTypes: []string{refund}, want: model.TransactionStatusRefunded},
+		{name: "refund then void", refundedQty: []int{2, 1}, refundTypes: []string{refund, void}, want: model.TransactionStatusRefunded},
+		{name: "void then refund", refundedQty: []int{2, 1}, refundTypes: []string{void, refund}, want: model.TransactionStatusRefunded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transaction := &model.Transaction{Details: []model.TransactionDetail{{Qty: 2}, {Qty: 1}}}
+			for i, qty := range tt.refundedQty {
+				transaction.Details[i].RefundedQty = qty
+			}
+
+			if got := refundStatus(transaction, tt.refundTypes); got != tt.want {
+				t.Errorf("refundStatus(%v) = %q, want %q", tt.refundTypes, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"service-cashier/internal/repository"
+	"strings"
+	"time"
+)
+
+// DefaultTopSellerLimit is the number of top sellers listed when no limit is requested
+const DefaultTopSellerLimit = 5
+
+// ErrInvalidReportQuery is returned when the report parameters are inconsistent
+var ErrInvalidReportQuery = errors.New("invalid report query")
+
+// ReportService handles sales reporting
+// outletCode is the outlet this deployment serves; the sales summary covers it
+// unless another outlet is requested
+type ReportService struct {
+	transactionRepo *repository.TransactionRepository
+	outletCode      string
+}
+
+// NewReportService creates a new ReportService instance
+func NewReportService(transactionRepo *repository.TransactionRepository, outletCode string) *ReportService {
+	return &ReportService{
+		transactionRepo: transactionRepo,
+		outletCode:      outletCode,
+	}
+}
+
+// MarginReportQuery represents the query parameters of the gross margin report
+// From and To accept a date (2006-01-02) or an RFC 3339 timestamp; a date in To
+// includes the whole day. GroupBy defaults to item
+type MarginReportQuery struct {
+	From    string `form:"from"`
+	To      string `form:"to"`
+	GroupBy string `form:"group_by" binding:"omitempty,oneof=item category day"`
+}
+
+// MarginReport is the gross margin of the sales in a period, per item, category or day
+type MarginReport struct {
+	GroupBy string                      `json:"group_by"`
+	Rows    []repository.SalesMarginRow `json:"rows"`
+	Totals  repository.SalesMarginRow   `json:"totals"`
+}
+
+// GetMarginReport aggregates net sales, cost of goods and gross margin
+func (s *ReportService) GetMarginReport(query *MarginReportQuery) (*MarginReport, error) {
+	filter := repository.SalesMarginFilter{GroupBy: query.GroupBy}
+	if filter.GroupBy == "" {
+		filter.GroupBy = repository.MarginByItem
+	}
+
+	var err error
+	if filter.From, filter.To, err = parseQueryTimeRange(query.From, query.To); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidReportQuery, err)
+	}
+
+	rows, err := s.transactionRepo.SalesMargins(filter)
+	if err != nil {
+		return nil, err
+	}
+	totals, err := s.transactionRepo.SalesMarginTotals(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MarginReport{GroupBy: filter.GroupBy, Rows: rows, Totals: totals}, nil
+}
+
+// SalesSummaryQuery represents the query parameters of the sales summary report
+// From and To accept a date (2006-01-02) or an RFC 3339 timestamp; a date in To
+// includes the whole day. Without either the report covers today. Outlet is the
+// outlet code the sales were rung up at and defaults to the outlet this
+// deployment serves. Limit caps each top seller list
+type SalesSummaryQuery struct {
+	From   string `form:"from"`
+	To     string `form:"to"`
+	Outlet string `form:"outlet" binding:"max=50"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=50"`
+}
+
+// SalesSummaryReport summarises the sales of an outlet over a period
+type SalesSummaryReport struct {
+	Outlet        string                      `json:"outlet"`
+	From          *time.Time                  `json:"from"`
+	To            *time.Time                  `json:"to"`
+	Summary       repository.SalesSummary     `json:"summary"`
+	TopByQuantity []repository.TopSellerRow   `json:"top_by_quantity"`
+	TopByRevenue  []repository.TopSellerRow   `json:"top_by_revenue"`
+	Hourly        []repository.HourlySalesRow `json:"hourly"`
+}
+
+// GetSalesSummary aggregates the sales, top sellers and hourly breakdown of a period
+// Voided transactions are left out, and refunds are taken off net amounts and units sold
+func (s *ReportService) GetSalesSummary(query *SalesSummaryQuery) (*SalesSummaryReport, error) {
+	outlet := strings.TrimSpace(query.Outlet)
+	if outlet == "" {
+		outlet = s.outletCode
+	}
+
+	from, to, err := parseQueryTimeRange(query.From, query.To)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidReportQuery, err)
+	}
+	if from == nil && to == nil {
+		now := time.Now()
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+		tomorrow := today.AddDate(0, 0, 1)
+		from, to = &today, &tomorrow
+	}
+
+	limit := query.Limit
+	if limit == 0 {
+		limit = DefaultTopSellerLimit
+	}
+
+	report := &SalesSummaryReport{Outlet: outlet, From: from, To: to}
+	if report.Summary, err = s.transactionRepo.SalesSummary(from, to, outlet); err != nil {
+		return nil, err
+	}
+	if report.TopByQuantity, err = s.transactionRepo.TopSellers(from, to, outlet, repository.TopSellersByQty, limit); err != nil {
+		return nil, err
+	}
+	if report.TopByRevenue, err = s.transactionRepo.TopSellers(from, to, outlet, repository.TopSellersByRevenue, limit); err != nil {
+		return nil, err
+	}
+	if report.Hourly, err = s.transactionRepo.HourlySales(from, to, outlet); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"service-cashier/internal/model"
+	"time"
+)
+
+// Stock alert levels
+const (
+	StockAlertLow     = "low"
+	StockAlertSoldOut = "sold_out"
+)
+
+// StockAlert reports a menu item or ingredient whose stock fell to its reorder
+// threshold or ran out
+// Menu alerts carry MenuID and MenuName; ingredient alerts carry IngredientID,
+// IngredientName and the Unit their stock is counted in
+type StockAlert struct {
+	MenuID           uint      `json:"menu_id,omitempty"`
+	MenuName         string    `json:"menu_name,omitempty"`
+	IngredientID     uint      `json:"ingredient_id,omitempty"`
+	IngredientName   string    `json:"ingredient_name,omitempty"`
+	Unit             string    `json:"unit,omitempty"`
+	Level            string    `json:"level"`
+	Stock            int       `json:"stock"`
+	ReorderThreshold int       `json:"reorder_threshold"`
+	MovementType     string    `json:"movement_type"`
+	TransactionID    *uint     `json:"transaction_id,omitempty"`
+	OccurredAt       time.Time `json:"occurred_at"`
+}
+
+// StockAlertNotifier delivers stock alerts once the stock change has been committed
+// Implementations must not block the caller for long, since they run on the checkout path
+type StockAlertNotifier interface {
+	NotifyStockAlerts(alerts []StockAlert)
+}
+
+// NewStockAlertNotifier returns a notifier posting to webhookURL, or one that
+// only writes alerts to the log when no webhook is configured
+func NewStockAlertNotifier(webhookURL string) StockAlertNotifier {
+	if webhookURL == "" {
+		return LogStockAlertNotifier{}
+	}
+	return NewWebhookStockAlertNotifier(webhookURL)
+}
+
+// LogStockAlertNotifier writes stock alerts to the application log
+type LogStockAlertNotifier struct{}
+
+// NotifyStockAlerts logs each alert
+func (LogStockAlertNotifier) NotifyStockAlerts(alerts []StockAlert) {
+	for _, alert := range alerts {
+		if alert.IngredientID != 0 {
+			log.Printf("Stock alert: '%s' (ingredient %d) is %s with %d %s left (reorder threshold %d)",
+				alert.IngredientName, alert.IngredientID, alert.Level, alert.Stock, alert.Unit, alert.ReorderThreshold)
+			continue
+		}
+		log.Printf("Stock alert: '%s' (menu %d) is %s with %d left (reorder threshold %d)",
+			alert.MenuName, alert.MenuID, alert.Level, alert.Stock, alert.ReorderThreshold)
+	}
+}
+
+// WebhookStockAlertNotifier posts stock alerts as JSON to a webhook URL
+// Alerts are sent in the background; delivery failures are logged and not retried
+type WebhookStockAlertNotifier struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookStockAlertNotifier creates a notifier posting to url
+func NewWebhookStockAlertNotifier(url string) *WebhookStockAlertNotifier {
+	return &WebhookStockAlertNotifier{
+		url:    url,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// NotifyStockAlerts posts the alerts as {"alerts": [...]} without waiting for the response
+func (n *WebhookStockAlertNotifier) NotifyStockAlerts(alerts []StockAlert) {
+	body, err := json.Marshal(map[string][]StockAlert{"alerts": alerts})
+	if err != nil {
+		log.Printf("Failed to encode stock alerts: %v", err)
+		return
+	}
+
+	go func() {
+		if err := n.post(body); err != nil {
+			log.Printf("Failed to deliver stock alerts: %v", err)
+			LogStockAlertNotifier{}.NotifyStockAlerts(alerts)
+		}
+	}()
+}
+
+// post sends one webhook request
+func (n *WebhookStockAlertNotifier) post(body []byte) error {
+	resp, err := n.client.Post(n.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// stockAlertFor reports whether a stock movement pushed a menu item to or below
+// its reorder threshold, or sold it out
+// Only the movement that crosses a level raises an alert, so an item that stays
+// low is not reported again on every sale
+func stockAlertFor(menu *model.Menu, movement *model.StockMovement) (StockAlert, bool) {
+	alert := StockAlert{
+		MenuID:           menu.ID,
+		MenuName:         menu.Name,
+		Stock:            movement.StockAfter,
+		ReorderThreshold: menu.ReorderThreshold,
+		MovementType:     movement.Type,
+		TransactionID:    movement.TransactionID,
+		OccurredAt:       time.Now(),
+	}
+
+	var ok bool
+	alert.Level, ok = stockAlertLevel(movement.StockBefore, movement.StockAfter, menu.ReorderThreshold)
+	return alert, ok
+}
+
+// ingredientStockAlertFor reports whether an ingredient movement pushed the
+// ingredient to or below its reorder threshold, or ran it out
+func ingredientStockAlertFor(ingredient *model.Ingredient, movement *model.IngredientMovement) (StockAlert, bool) {
+	alert := StockAlert{
+		IngredientID:     ingredient.ID,
+		IngredientName:   ingredient.Name,
+		Unit:             ingredient.Unit,
+		Stock:            movement.StockAfter,
+		ReorderThreshold: ingredient.ReorderThreshold,
+		MovementType:     movement.Type,
+		TransactionID:    movement.TransactionID,
+		OccurredAt:       time.Now(),
+	}
+
+	var ok bool
+	alert.Level, ok = stockAlertLevel(movement.StockBefore, movement.StockAfter, ingredient.ReorderThreshold)
+	return alert, ok
+}
+
+// stockAlertLevel returns the level a stock change crossed into, if any
+func stockAlertLevel(before, after, threshold int) (string, bool) {
+	switch {
+	case after <= 0 && before > 0:
+		return StockAlertSoldOut, true
+	case threshold > 0 && after <= threshold && before > threshold:
+		return StockAlertLow, true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,576 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"service-cashier/internal/model"
+	"service-cashier/internal/repository"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrStockTakeNotFound is returned when the requested stock take does not exist
+	ErrStockTakeNotFound = errors.New("stock take not found")
+	// ErrStockTakeClosed is returned when a stock take that was approved or cancelled is changed
+	ErrStockTakeClosed = errors.New("stock take is no longer open")
+	// ErrStockTakeIncomplete is returned when a stock take with uncounted items is approved
+	ErrStockTakeIncomplete = errors.New("every item must be counted before approval")
+	// ErrInvalidStockTake is returned when a stock take names items that cannot be counted
+	ErrInvalidStockTake = errors.New("invalid stock take")
+)
+
+// StockTakeService handles stock take sessions and their variance
+//
+// A session snapshots the stock of its items and marks the end of the stock
+// ledger when it starts. Each submitted count marks the ledger again, so the
+// movements made while staff were counting (sales, refunds and so on) are
+// known exactly. Menu items tracked by their own stock and ingredients are
+// counted alike, each against its own ledger. For each item:
+//
+//	expected = system stock at start + movements during the count
+//	variance = counted - expected
+//
+// Approval posts every non-zero variance as a stock_take movement in one
+// database transaction, on top of whatever stock the item has by then.
+type StockTakeService struct {
+	stockTakeRepo     *repository.StockTakeRepository
+	menuRepo          *repository.MenuRepository
+	stockMovementRepo *repository.StockMovementRepository
+	ingredientRepo    *repository.IngredientRepository
+	stockNotifier     StockAlertNotifier
+}
+
+// NewStockTakeService creates a new StockTakeService instance
+func NewStockTakeService(stockTakeRepo *repository.StockTakeRepository, menuRepo *repository.MenuRepository, stockMovementRepo *repository.StockMovementRepository, ingredientRepo *repository.IngredientRepository, stockNotifier StockAlertNotifier) *StockTakeService {
+	return &StockTakeService{
+		stockTakeRepo:     stockTakeRepo,
+		menuRepo:          menuRepo,
+		stockMovementRepo: stockMovementRepo,
+		ingredientRepo:    ingredientRepo,
+		stockNotifier:     stockNotifier,
+	}
+}
+
+// CreateStockTakeRequest represents the create stock take payload
+// When both lists are empty every menu item tracked by its own stock and every
+// ingredient is counted; otherwise only the listed ones are
+type CreateStockTakeRequest struct {
+	MenuIDs       []uint `json:"menu_ids" binding:"omitempty,dive,required"`
+	IngredientIDs []uint `json:"ingredient_ids" binding:"omitempty,dive,required"`
+	Note          string `json:"note" binding:"max=255"`
+}
+
+// StockTakeCount represents the counted quantity of one menu item or ingredient
+// Exactly one of MenuID and IngredientID must be set; ingredients are counted
+// in their base unit
+type StockTakeCount struct {
+	MenuID       *uint `json:"menu_id"`
+	IngredientID *uint `json:"ingredient_id"`
+	CountedQty   *int  `json:"counted_qty" binding:"required,min=0"`
+}
+
+// StockTakeCountRequest represents the payload submitting counted quantities
+// Counting an item again replaces its previous count
+type StockTakeCountRequest struct {
+	Counts []StockTakeCount `json:"counts" binding:"required,min=1,dive"`
+}
+
+// StockTakeListQuery represents the query parameters of the stock take listing
+type StockTakeListQuery struct {
+	Status string `form:"status" binding:"omitempty,oneof=open approved cancelled"`
+}
+
+// GetStockTakes retrieves stock takes newest first
+func (s *StockTakeService) GetStockTakes(query *StockTakeListQuery) ([]model.StockTake, error) {
+	return s.stockTakeRepo.GetAll(query.Status)
+}
+
+// GetStockTakeByID retrieves a stock take with its lines
+// For open sessions the variance of the counted lines is a preview computed from
+// the current ledger; approved sessions show the variance that was posted
+func (s *StockTakeService) GetStockTakeByID(id uint) (*model.StockTake, error) {
+	stockTake, err := s.stockTakeRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrStockTakeNotFound
+		}
+		return nil, err
+	}
+
+	if stockTake.Status != model.StockTakeOpen {
+		return stockTake, nil
+	}
+
+	// Read the ledger in one snapshot; nothing is written
+	tx := s.stockTakeRepo.BeginTransaction()
+	defer tx.Rollback()
+
+	for i := range stockTake.Lines {
+		if err := s.computeVariance(tx, stockTake, &stockTake.Lines[i]); err != nil {
+			return nil, err
+		}
+	}
+	return stockTake, nil
+}
+
+// CreateStockTake starts a stock take of the given menu items and ingredients
+// Menu items with a recipe are not counted, since their stock lives in their
+// ingredients. Menu rows are locked before ingredient rows, as in checkout
+func (s *StockTakeService) CreateStockTake(actorID uint, req *CreateStockTakeRequest) (*model.StockTake, error) {
+	tx := s.stockTakeRepo.BeginTransaction()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// Lock the items so the stock snapshot and the ledger marks agree
+	everything := len(req.MenuIDs) == 0 && len(req.IngredientIDs) == 0
+
+	var menus []model.Menu
+	var err error
+	if everything || len(req.MenuIDs) > 0 {
+		if menus, err = s.menuRepo.FindStockTrackedWithLock(tx, req.MenuIDs); err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("failed to fetch menu items: %w", err)
+		}
+	}
+	if err := ensureAllFound(req.MenuIDs, menus); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	var ingredients []model.Ingredient
+	if everything {
+		ingredients, err = s.ingredientRepo.FindAllWithLock(tx)
+	} else {
+		ingredients, err = s.ingredientRepo.FindByIDsWithLock(tx, req.IngredientIDs)
+	}
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to fetch ingredients: %w", err)
+	}
+	if err := ensureIngredientsFound(req.IngredientIDs, ingredients); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if len(menus) == 0 && len(ingredients) == 0 {
+		tx.Rollback()
+		return nil, fmt.Errorf("%w: no menu items or ingredients to count", ErrInvalidStockTake)
+	}
+
+	menuMark, err := s.stockMovementRepo.LatestID(tx)
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to read stock ledger: %w", err)
+	}
+	ingredientMark, err := s.ingredientRepo.LatestMovementID(tx)
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to read ingredient ledger: %w", err)
+	}
+
+	stockTake := &model.StockTake{
+		Status:                    model.StockTakeOpen,
+		Note:                      strings.TrimSpace(req.Note),
+		StartMovementID:           menuMark,
+		StartIngredientMovementID: ingredientMark,
+		CreatedByID:               actorID,
+	}
+	for _, menu := range menus {
+		menuID := menu.ID
+		stockTake.Lines = append(stockTake.Lines, model.StockTakeLine{
+			MenuID:      &menuID,
+			Name:        menu.Name,
+			SystemStock: menu.Stock,
+		})
+	}
+	for _, ingredient := range ingredients {
+		ingredientID := ingredient.ID
+		stockTake.Lines = append(stockTake.Lines, model.StockTakeLine{
+			IngredientID: &ingredientID,
+			Name:         ingredient.Name,
+			Unit:         ingredient.Unit,
+			SystemStock:  ingredient.Stock,
+		})
+	}
+
+	if err := s.stockTakeRepo.Create(tx, stockTake); err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to create stock take: %w", err)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return stockTake, nil
+}
+
+// SubmitCounts records counted quantities for items of an open stock take
+func (s *StockTakeService) SubmitCounts(actorID, id uint, req *StockTakeCountRequest) (*model.StockTake, error) {
+	tx := s.stockTakeRepo.BeginTransaction()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	stockTake, err := s.lockOpenStockTake(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	menuLines := make(map[uint]*model.StockTakeLine)
+	ingredientLines := make(map[uint]*model.StockTakeLine)
+	for i := range stockTake.Lines {
+		line := &stockTake.Lines[i]
+		if line.MenuID != nil {
+			menuLines[*line.MenuID] = line
+		} else if line.IngredientID != nil {
+			ingredientLines[*line.IngredientID] = line
+		}
+	}
+
+	menuCounts := make(map[uint]int)
+	ingredientCounts := make(map[uint]int)
+	for _, count := range req.Counts {
+		switch {
+		case count.MenuID != nil && count.IngredientID == nil:
+			if menuLines[*count.MenuID] == nil {
+				tx.Rollback()
+				return nil, fmt.Errorf("%w: menu item %d is not part of this stock take", ErrInvalidStockTake, *count.MenuID)
+			}
+			menuCounts[*count.MenuID] = *count.CountedQty
+		case count.IngredientID != nil && count.MenuID == nil:
+			if ingredientLines[*count.IngredientID] == nil {
+				tx.Rollback()
+				return nil, fmt.Errorf("%w: ingredient %d is not part of this stock take", ErrInvalidStockTake, *count.IngredientID)
+			}
+			ingredientCounts[*count.IngredientID] = *count.CountedQty
+		default:
+			tx.Rollback()
+			return nil, fmt.Errorf("%w: each count needs either a menu_id or an ingredient_id", ErrInvalidStockTake)
+		}
+	}
+	menuIDs := sortedIDs(menuCounts)
+	ingredientIDs := sortedIDs(ingredientCounts)
+
+	// Lock the counted items so no sale lands between the count and its ledger mark
+	for _, menuID := range menuIDs {
+		if _, err := s.menuRepo.FindByIDWithLockUnscoped(tx, menuID); err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("failed to fetch menu item %d: %w", menuID, err)
+		}
+	}
+	if _, err := s.ingredientRepo.FindByIDsWithLock(tx, ingredientIDs); err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to fetch ingredients: %w", err)
+	}
+
+	menuMark, err := s.stockMovementRepo.LatestID(tx)
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to read stock ledger: %w", err)
+	}
+	ingredientMark, err := s.ingredientRepo.LatestMovementID(tx)
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to read ingredient ledger: %w", err)
+	}
+
+	now := time.Now()
+	record := func(line *model.StockTakeLine, counted int, mark uint) error {
+		line.CountedQty = &counted
+		line.CountMovementID = mark
+		line.CountedByID = &actorID
+		line.CountedAt = &now
+		if err := s.stockTakeRepo.UpdateLine(tx, line); err != nil {
+			return fmt.Errorf("failed to record count: %w", err)
+		}
+		return nil
+	}
+	for _, menuID := range menuIDs {
+		if err := record(menuLines[menuID], menuCounts[menuID], menuMark); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+	for _, ingredientID := range ingredientIDs {
+		if err := record(ingredientLines[ingredientID], ingredientCounts[ingredientID], ingredientMark); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return s.GetStockTakeByID(id)
+}
+
+// ApproveStockTake posts the variance of every item as a stock_take movement and
+// closes the session, all within a single database transaction
+func (s *StockTakeService) ApproveStockTake(actorID, id uint) (*model.StockTake, error) {
+	tx := s.stockTakeRepo.BeginTransaction()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	stockTake, err := s.lockOpenStockTake(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	var stockAlerts []StockAlert
+	reason := fmt.Sprintf("Stock take #%d", stockTake.ID)
+
+	// Lines are ordered by menu ID and then ingredient ID, the order checkout
+	// locks menu items and ingredients in
+	for i := range stockTake.Lines {
+		line := &stockTake.Lines[i]
+		if line.CountedQty == nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("%w: '%s' has not been counted", ErrStockTakeIncomplete, line.Name)
+		}
+
+		var alert *StockAlert
+		if line.MenuID != nil {
+			alert, err = s.postMenuVariance(tx, stockTake, line, actorID, reason)
+		} else {
+			alert, err = s.postIngredientVariance(tx, stockTake, line, actorID, reason)
+		}
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		if alert != nil {
+			stockAlerts = append(stockAlerts, *alert)
+		}
+	}
+
+	if err := s.closeStockTake(tx, stockTake, actorID, model.StockTakeApproved); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	if len(stockAlerts) > 0 {
+		s.stockNotifier.NotifyStockAlerts(stockAlerts)
+	}
+
+	return stockTake, nil
+}
+
+// CancelStockTake closes an open stock take without changing any stock
+func (s *StockTakeService) CancelStockTake(actorID, id uint) (*model.StockTake, error) {
+	tx := s.stockTakeRepo.BeginTransaction()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	stockTake, err := s.lockOpenStockTake(tx, id)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := s.closeStockTake(tx, stockTake, actorID, model.StockTakeCancelled); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return stockTake, nil
+}
+
+// postMenuVariance locks the menu item of a line, finalises its variance and
+// posts it as a stock_take movement, returning any stock alert it raises
+func (s *StockTakeService) postMenuVariance(tx *gorm.DB, stockTake *model.StockTake, line *model.StockTakeLine, actorID uint, reason string) (*StockAlert, error) {
+	menu, err := s.menuRepo.FindByIDWithLockUnscoped(tx, *line.MenuID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch menu item %d: %w", *line.MenuID, err)
+	}
+
+	if err := s.finaliseLine(tx, stockTake, line); err != nil {
+		return nil, err
+	}
+	if line.Variance == 0 {
+		return nil, nil
+	}
+	if menu.Stock+line.Variance < 0 {
+		return nil, fmt.Errorf("%w: '%s' has %d in stock and a variance of %d", ErrNegativeStock, menu.Name, menu.Stock, line.Variance)
+	}
+
+	movement := &model.StockMovement{
+		Type:        model.StockMovementStockTake,
+		Quantity:    line.Variance,
+		UserID:      &actorID,
+		Reason:      reason,
+		StockTakeID: &stockTake.ID,
+	}
+	if err := s.menuRepo.ApplyStockMovement(tx, menu, movement); err != nil {
+		return nil, fmt.Errorf("failed to update stock: %w", err)
+	}
+	if alert, ok := stockAlertFor(menu, movement); ok {
+		return &alert, nil
+	}
+	return nil, nil
+}
+
+// postIngredientVariance locks the ingredient of a line, finalises its variance
+// and posts it as a stock_take movement, returning any stock alert it raises
+func (s *StockTakeService) postIngredientVariance(tx *gorm.DB, stockTake *model.StockTake, line *model.StockTakeLine, actorID uint, reason string) (*StockAlert, error) {
+	ingredient, err := s.ingredientRepo.FindByIDWithLock(tx, *line.IngredientID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: ingredient '%s' no longer exists", ErrInvalidStockTake, line.Name)
+		}
+		return nil, fmt.Errorf("failed to fetch ingredient %d: %w", *line.IngredientID, err)
+	}
+
+	if err := s.finaliseLine(tx, stockTake, line); err != nil {
+		return nil, err
+	}
+	if line.Variance == 0 {
+		return nil, nil
+	}
+	if ingredient.Stock+line.Variance < 0 {
+		return nil, fmt.Errorf("%w: '%s' has %d %s in stock and a variance of %d", ErrNegativeStock, ingredient.Name, ingredient.Stock, ingredient.Unit, line.Variance)
+	}
+
+	movement := &model.IngredientMovement{
+		Type:        model.StockMovementStockTake,
+		Quantity:    line.Variance,
+		UserID:      &actorID,
+		Reason:      reason,
+		StockTakeID: &stockTake.ID,
+	}
+	if err := s.ingredientRepo.ApplyMovement(tx, ingredient, movement); err != nil {
+		return nil, fmt.Errorf("failed to update ingredient stock: %w", err)
+	}
+	if alert, ok := ingredientStockAlertFor(ingredient, movement); ok {
+		return &alert, nil
+	}
+	return nil, nil
+}
+
+// finaliseLine computes the final variance of a line and saves it
+func (s *StockTakeService) finaliseLine(tx *gorm.DB, stockTake *model.StockTake, line *model.StockTakeLine) error {
+	if err := s.computeVariance(tx, stockTake, line); err != nil {
+		return err
+	}
+	if err := s.stockTakeRepo.UpdateLine(tx, line); err != nil {
+		return fmt.Errorf("failed to update stock take line: %w", err)
+	}
+	return nil
+}
+
+// lockOpenStockTake locks a stock take and its lines and checks that it is still open
+func (s *StockTakeService) lockOpenStockTake(tx *gorm.DB, id uint) (*model.StockTake, error) {
+	stockTake, err := s.stockTakeRepo.FindByIDWithLock(tx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrStockTakeNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch stock take: %w", err)
+	}
+	if stockTake.Status != model.StockTakeOpen {
+		return nil, ErrStockTakeClosed
+	}
+	return stockTake, nil
+}
+
+// closeStockTake marks a stock take approved or cancelled by actorID
+func (s *StockTakeService) closeStockTake(tx *gorm.DB, stockTake *model.StockTake, actorID uint, status string) error {
+	now := time.Now()
+	stockTake.Status = status
+	stockTake.ClosedByID = &actorID
+	stockTake.ClosedAt = &now
+	if err := s.stockTakeRepo.Update(tx, stockTake); err != nil {
+		return fmt.Errorf("failed to update stock take: %w", err)
+	}
+	return nil
+}
+
+// computeVariance fills in the movements during the count, the expected stock
+// and the variance of a counted line
+// Uncounted lines are left at zero
+func (s *StockTakeService) computeVariance(tx *gorm.DB, stockTake *model.StockTake, line *model.StockTakeLine) error {
+	if line.CountedQty == nil {
+		return nil
+	}
+
+	var during int
+	var err error
+	if line.MenuID != nil {
+		during, err = s.stockMovementRepo.SumBetween(tx, *line.MenuID, stockTake.StartMovementID, line.CountMovementID)
+	} else {
+		during, err = s.ingredientRepo.SumMovementsBetween(tx, *line.IngredientID, stockTake.StartIngredientMovementID, line.CountMovementID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read stock ledger: %w", err)
+	}
+
+	line.MovementsDuringCount = during
+	line.ExpectedStock = line.SystemStock + during
+	line.Variance = *line.CountedQty - line.ExpectedStock
+	return nil
+}
+
+// ensureAllFound returns ErrInvalidStockTake when a requested menu item was not
+// found among the countable items
+func ensureAllFound(menuIDs []uint, menus []model.Menu) error {
+	found := make(map[uint]bool, len(menus))
+	for _, menu := range menus {
+		found[menu.ID] = true
+	}
+	for _, menuID := range menuIDs {
+		if !found[menuID] {
+			return fmt.Errorf("%w: menu item %d does not exist or is made from a recipe", ErrInvalidStockTake, menuID)
+		}
+	}
+	return nil
+}
+
+// ensureIngredientsFound returns ErrInvalidStockTake when a requested ingredient does not exist
+func ensureIngredientsFound(ingredientIDs []uint, ingredients []model.Ingredient) error {
+	found := make(map[uint]bool, len(ingredients))
+	for _, ingredient := range ingredients {
+		found[ingredient.ID] = true
+	}
+	for _, ingredientID := range ingredientIDs {
+		if !found[ingredientID] {
+			return fmt.Errorf("%w: ingredient %d does not exist", ErrInvalidStockTake, ingredientID)
+		}
+	}
+	return nil
+}
+
+// sortedIDs returns the keys of counts in ascending order
+func sortedIDs(counts map[uint]int) []uint {
+	ids := make([]uint, 0, len(counts))
+	for id := range counts {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"errors"
+	"service-cashier/internal/model"
+	"service-cashier/internal/repository"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrSupplierNotFound is returned when the requested supplier does not exist
+	ErrSupplierNotFound = errors.New("supplier not found")
+	// ErrSupplierNameExists is returned when another supplier already uses the name
+	ErrSupplierNameExists = errors.New("supplier with this name already exists")
+	// ErrSupplierNameRequired is returned when the supplier name is blank after trimming
+	ErrSupplierNameRequired = errors.New("supplier name cannot be empty")
+	// ErrSupplierInUse is returned when deleting a supplier that has purchase orders
+	ErrSupplierInUse = errors.New("supplier has purchase orders; deactivate it instead")
+	// ErrSupplierInactive is returned when a purchase order is raised with a deactivated supplier
+	ErrSupplierInactive = errors.New("supplier is inactive")
+)
+
+// SupplierService handles supplier business logic
+type SupplierService struct {
+	supplierRepo *repository.SupplierRepository
+}
+
+// NewSupplierService creates a new SupplierService instance
+func NewSupplierService(supplierRepo *repository.SupplierRepository) *SupplierService {
+	return &SupplierService{supplierRepo: supplierRepo}
+}
+
+// SupplierRequest represents the create and update supplier payload
+// Active defaults to true when omitted
+type SupplierRequest struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	ContactName string `json:"contact_name" binding:"max=100"`
+	Phone       string `json:"phone" binding:"max=30"`
+	Email       string `json:"email" binding:"omitempty,email,max=100"`
+	Address     string `json:"address" binding:"max=255"`
+	Active      *bool  `json:"active"`
+}
+
+// SupplierListQuery represents the query parameters of the supplier listing
+type SupplierListQuery struct {
+	ActiveOnly bool `form:"active"`
+}
+
+// GetAllSuppliers retrieves suppliers ordered by name
+func (s *SupplierService) GetAllSuppliers(query *SupplierListQuery) ([]model.Supplier, error) {
+	return s.supplierRepo.GetAll(query.ActiveOnly)
+}
+
+// GetSupplierByID retrieves a supplier by ID
+func (s *SupplierService) GetSupplierByID(id uint) (*model.Supplier, error) {
+	supplier, err := s.supplierRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSupplierNotFound
+		}
+		return nil, err
+	}
+	return supplier, nil
+}
+
+// CreateSupplier creates a new supplier
+func (s *SupplierService) CreateSupplier(req *SupplierRequest) (*model.Supplier, error) {
+	supplier := &model.Supplier{Active: true}
+	if err := applySupplierRequest(supplier, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.supplierRepo.Create(supplier); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrSupplierNameExists
+		}
+		return nil, err
+	}
+	return supplier, nil
+}
+
+// UpdateSupplier replaces the editable fields of an existing supplier
+func (s *SupplierService) UpdateSupplier(id uint, req *SupplierRequest) (*model.Supplier, error) {
+	supplier, err := s.GetSupplierByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applySupplierRequest(supplier, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.supplierRepo.Update(supplier); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrSupplierNameExists
+		}
+		return nil, err
+	}
+	return supplier, nil
+}
+
+// DeleteSupplier deletes a supplier that has no purchase orders
+func (s *SupplierService) DeleteSupplier(id uint) error {
+	if _, err := s.GetSupplierByID(id); err != nil {
+		return err
+	}
+
+	used, err := s.supplierRepo.HasPurchaseOrders(id)
+	if err != nil {
+		return err
+	}
+	if used {
+		return ErrSupplierInUse
+	}
+
+	return s.supplierRepo.Delete(id)
+}
+
+// applySupplierRequest copies the request fields onto the supplier
+func applySupplierRequest(supplier *model.Supplier, req *SupplierRequest) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return ErrSupplierNameRequired
+	}
+
+	supplier.Name = name
+	supplier.ContactName = strings.TrimSpace(req.ContactName)
+	supplier.Phone = strings.TrimSpace(req.Phone)
+	supplier.Email = strings.TrimSpace(req.Email)
+	supplier.Address = strings.TrimSpace(req.Address)
+	if req.Active != nil {
+		supplier.Active = *req.Active
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"fmt"
+	"service-cashier/pkg/money"
+)
+
+// TaxRules configures how tax and service charge are applied at checkout
+//
+// The service charge is always added on top of the bill and is calculated on
+// amounts net of tax. Tax applies to every line that is not tax exempt and to
+// the service charge on those lines:
+//   - Exclusive pricing: tax = (taxable lines + their service charge) × rate
+//   - Inclusive pricing: menu prices already contain tax, so the tax on taxable
+//     lines is extracted as amount × rate / (100% + rate) and only the tax on
+//     their service charge is added to the bill
+//
+// Every step is rounded half away from zero to the nearest minor unit.
+type TaxRules struct {
+	TaxRate           money.Rate
+	TaxInclusive      bool
+	ServiceChargeRate money.Rate
+}
+
+// NewTaxRules parses tax and service charge percentages from configuration
+func NewTaxRules(taxRate string, inclusive bool, serviceChargeRate string) (TaxRules, error) {
+	rate, err := money.ParseRate(taxRate)
+	if err != nil {
+		return TaxRules{}, fmt.Errorf("tax rate: %w", err)
+	}
+
+	serviceRate, err := money.ParseRate(serviceChargeRate)
+	if err != nil {
+		return TaxRules{}, fmt.Errorf("service charge rate: %w", err)
+	}
+
+	return TaxRules{
+		TaxRate:           rate,
+		TaxInclusive:      inclusive,
+		ServiceChargeRate: serviceRate,
+	}, nil
+}
+
+// TaxableLine is a single sale line evaluated by the tax engine
+type TaxableLine struct {
+	Amount    money.Money
+	TaxExempt bool
+}
+
+// LineTax is the share of service charge and tax allocated to a single line
+// Total is what the customer pays for the line including additions
+type LineTax struct {
+	ServiceCharge money.Money
+	TaxAmount     money.Money
+	Total         money.Money
+}
+
+// TaxBreakdown is the result of applying TaxRules to a set of lines
+type TaxBreakdown struct {
+	Subtotal      money.Money
+	ServiceCharge money.Money
+	TaxAmount     money.Money
+	GrandTotal    money.Money
+	Lines         []LineTax
+}
+
+// Calculate applies the rules to the given lines
+// Order-level amounts are allocated back to the lines so that the line totals
+// always add up to the grand total, which keeps partial refunds exact
+func (r TaxRules) Calculate(lines []TaxableLine) TaxBreakdown {
+	var subtotal, taxableGross, exemptGross money.Money
+	taxableWeights := make([]money.Money, len(lines))
+	exemptWeights := make([]money.Money, len(lines))
+
+	for i, line := range lines {
+		subtotal = subtotal.Add(line.Amount)
+		if line.TaxExempt {
+			exemptGross = exemptGross.Add(line.Amount)
+			exemptWeights[i] = line.Amount
+		} else {
+			taxableGross = taxableGross.Add(line.Amount)
+			taxableWeights[i] = line.Amount
+		}
+	}
+
+	// Tax already contained in inclusive prices
+	var includedTax money.Money
+	if r.TaxInclusive {
+		includedTax = taxableGross.IncludedTax(r.TaxRate)
+	}
+	taxableNet := taxableGross.Sub(includedTax)
+
+	serviceTaxable := taxableNet.MulRate(r.ServiceChargeRate)
+	serviceExempt := exemptGross.MulRate(r.ServiceChargeRate)
+
+	// Tax that is added on top of the menu prices
+	var addedTax money.Money
+	if r.TaxInclusive {
+		addedTax = serviceTaxable.MulRate(r.TaxRate)
+	} else {
+		addedTax = taxableNet.Add(serviceTaxable).MulRate(r.TaxRate)
+	}
+
+	serviceCharge := serviceTaxable.Add(serviceExempt)
+	breakdown := TaxBreakdown{
+		Subtotal:      subtotal,
+		ServiceCharge: serviceCharge,
+		TaxAmount:     includedTax.Add(addedTax),
+		GrandTotal:    subtotal.Add(serviceCharge).Add(addedTax),
+		Lines:         make([]LineTax, len(lines)),
+	}
+
+	includedShares := money.Allocate(includedTax, taxableWeights)
+	addedShares := money.Allocate(addedTax, taxableWeights)
+	serviceTaxableShares := money.Allocate(serviceTaxable, taxableWeights)
+	serviceExemptShares := money.Allocate(serviceExempt, exemptWeights)
+
+	for i, line := range lines {
+		service := serviceTaxableShares[i].Add(serviceExemptShares[i])
+		breakdown.Lines[i] = LineTax{
+			ServiceCharge: service,
+			TaxAmount:     includedShares[i].Add(addedShares[i]),
+			Total:         line.Amount.Add(service).Add(addedShares[i]),
+		}
+	}
+
+	return breakdown
+}
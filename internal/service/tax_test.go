@@ -0,0 +1,169 @@
+package service
+
+import (
+	"service-cashier/pkg/money"
+	"testing"
+)
+
+func TestNewTaxRules(t *testing.T) {
+	tests := []struct {
+		name        string
+		taxRate     string
+		serviceRate string
+		want        TaxRules
+		wantErr     bool
+	}{
+		{name: "rates", taxRate: "11", serviceRate: "5", want: TaxRules{TaxRate: 1100, ServiceChargeRate: 500}},
+		{name: "empty rates", taxRate: "", serviceRate: "", want: TaxRules{}},
+		{name: "invalid tax rate", taxRate: "abc", serviceRate: "5", wantErr: true},
+		{name: "invalid service charge rate", taxRate: "11", serviceRate: "-5", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewTaxRules(tt.taxRate, false, tt.serviceRate)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("NewTaxRules() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTaxRulesCalculate(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules TaxRules
+		lines []TaxableLine
+		want  TaxBreakdown
+	}{
+		{
+			name:  "no tax or service charge",
+			rules: TaxRules{},
+			lines: []TaxableLine{{Amount: 1000}, {Amount: 2000}},
+			want: TaxBreakdown{
+				Subtotal:   3000,
+				GrandTotal: 3000,
+				Lines:      []LineTax{{Total: 1000}, {Total: 2000}},
+			},
+		},
+		{
+			name:  "exclusive tax",
+			rules: TaxRules{TaxRate: 1100},
+			lines: []TaxableLine{{Amount: 10000}, {Amount: 5000}},
+			want: TaxBreakdown{
+				Subtotal:   15000,
+				TaxAmount:  1650,
+				GrandTotal: 16650,
+				Lines: []LineTax{
+					{TaxAmount: 1100, Total: 11100},
+					{TaxAmount: 550, Total: 5550},
+				},
+			},
+		},
+		{
+			name:  "exclusive tax on service charge",
+			rules: TaxRules{TaxRate: 1100, ServiceChargeRate: 500},
+			lines: []TaxableLine{{Amount: 10000}},
+			want: TaxBreakdown{
+				Subtotal:      10000,
+				ServiceCharge: 500,
+				TaxAmount:     1155,
+				GrandTotal:    11655,
+				Lines:         []LineTax{{ServiceCharge: 500, TaxAmount: 1155, Total: 11655}},
+			},
+		},
+		{
+			name:  "inclusive tax adds only the tax on service charge",
+			rules: TaxRules{TaxRate: 1100, TaxInclusive: true, ServiceChargeRate: 500},
+			lines: []TaxableLine{{Amount: 11100}},
+			want: TaxBreakdown{
+				Subtotal:      11100,
+				ServiceCharge: 500,
+				TaxAmount:     1155,
+				GrandTotal:    11655,
+				Lines:         []LineTax{{ServiceCharge: 500, TaxAmount: 1155, Total: 11655}},
+			},
+		},
+		{
+			name:  "inclusive tax is rounded",
+			rules: TaxRules{TaxRate: 1000, TaxInclusive: true},
+			lines: []TaxableLine{{Amount: 1000}},
+			want: TaxBreakdown{
+				Subtotal:   1000,
+				TaxAmount:  91,
+				GrandTotal: 1000,
+				Lines:      []LineTax{{TaxAmount: 91, Total: 1000}},
+			},
+		},
+		{
+			name:  "exempt lines pay service charge but no tax",
+			rules: TaxRules{TaxRate: 1000, ServiceChargeRate: 1000},
+			lines: []TaxableLine{{Amount: 1000}, {Amount: 500, TaxExempt: true}},
+			want: TaxBreakdown{
+				Subtotal:      1500,
+				ServiceCharge: 150,
+				TaxAmount:     110,
+				GrandTotal:    1760,
+				Lines: []LineTax{
+					{ServiceCharge: 100, TaxAmount: 110, Total: 1210},
+					{ServiceCharge: 50, Total: 550},
+				},
+			},
+		},
+		{
+			name:  "order tax is allocated by largest remainder",
+			rules: TaxRules{TaxRate: 1100},
+			lines: []TaxableLine{{Amount: 333}, {Amount: 333}, {Amount: 334}},
+			want: TaxBreakdown{
+				Subtotal:   1000,
+				TaxAmount:  110,
+				GrandTotal: 1110,
+				Lines: []LineTax{
+					{TaxAmount: 37, Total: 370},
+					{TaxAmount: 36, Total: 369},
+					{TaxAmount: 37, Total: 371},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.rules.Calculate(tt.lines)
+
+			if got.Subtotal != tt.want.Subtotal || got.ServiceCharge != tt.want.ServiceCharge ||
+				got.TaxAmount != tt.want.TaxAmount || got.GrandTotal != tt.want.GrandTotal {
+				t.Errorf("totals = %s/%s/%s/%s, want %s/%s/%s/%s",
+					got.Subtotal, got.ServiceCharge, got.TaxAmount, got.GrandTotal,
+					tt.want.Subtotal, tt.want.ServiceCharge, tt.want.TaxAmount, tt.want.GrandTotal)
+			}
+
+			if len(got.Lines) != len(tt.want.Lines) {
+				t.Fatalf("got %d lines, want %d", len(got.Lines), len(tt.want.Lines))
+			}
+			var lineTotal, lineTax, lineService money.Money
+			for i, line := range got.Lines {
+				if line != tt.want.Lines[i] {
+					t.Errorf("line %d = %+v, want %+v", i, line, tt.want.Lines[i])
+				}
+				lineTotal = lineTotal.Add(line.Total)
+				lineTax = lineTax.Add(line.TaxAmount)
+				lineService = lineService.Add(line.ServiceCharge)
+			}
+
+			if lineTotal != got.GrandTotal || lineTax != got.TaxAmount || lineService != got.ServiceCharge {
+				t.Errorf("line amounts %s/%s/%s do not add up to the order %s/%s/%s",
+					lineService, lineTax, lineTotal, got.ServiceCharge, got.TaxAmount, got.GrandTotal)
+			}
+		})
+	}
+}
@@ -1,64 +1,151 @@
 package service
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"service-cashier/internal/model"
 	"service-cashier/internal/repository"
+	"service-cashier/pkg/money"
+	"sort"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
 )
 
+var (
+	// ErrIdempotencyKeyConflict is returned when an idempotency key is reused with a different payload
+	ErrIdempotencyKeyConflict = errors.New("idempotency key was already used with a different request payload")
+	// ErrInvalidPayment is returned when the payment lines cannot settle the transaction
+	ErrInvalidPayment = errors.New("invalid payment")
+	// ErrInvalidTransactionQuery is returned when the transaction history parameters are inconsistent
+	ErrInvalidTransactionQuery = errors.New("invalid transaction query")
+)
+
 // TransactionService handles transaction business logic
 type TransactionService struct {
 	transactionRepo *repository.TransactionRepository
 	menuRepo        *repository.MenuRepository
+	promotionRepo   *repository.PromotionRepository
+	modifierRepo    *repository.ModifierRepository
+	ingredientRepo  *repository.IngredientRepository
+	taxRules        TaxRules
+	discountPolicy  DiscountPolicy
+	stockNotifier   StockAlertNotifier
+	outletCode      string
 }
 
 // NewTransactionService creates a new TransactionService instance
-func NewTransactionService(transactionRepo *repository.TransactionRepository, menuRepo *repository.MenuRepository) *TransactionService {
+func NewTransactionService(transactionRepo *repository.TransactionRepository, menuRepo *repository.MenuRepository, promotionRepo *repository.PromotionRepository, modifierRepo *repository.ModifierRepository, ingredientRepo *repository.IngredientRepository, taxRules TaxRules, discountPolicy DiscountPolicy, stockNotifier StockAlertNotifier, outletCode string) *TransactionService {
 	return &TransactionService{
 		transactionRepo: transactionRepo,
 		menuRepo:        menuRepo,
+		promotionRepo:   promotionRepo,
+		modifierRepo:    modifierRepo,
+		ingredientRepo:  ingredientRepo,
+		taxRules:        taxRules,
+		discountPolicy:  discountPolicy,
+		stockNotifier:   stockNotifier,
+		outletCode:      outletCode,
 	}
 }
 
 // CheckoutItem represents a single item in a checkout request
+// ModifierOptionIDs are the modifier options chosen for the item, such as a size or add-ons
 type CheckoutItem struct {
-	MenuID uint `json:"menu_id" binding:"required"`
-	Qty    int  `json:"qty" binding:"required,min=1"`
+	MenuID            uint            `json:"menu_id" binding:"required"`
+	Qty               int             `json:"qty" binding:"required,min=1"`
+	ModifierOptionIDs []uint          `json:"modifier_option_ids" binding:"omitempty,dive,required"`
+	Discount          *ManualDiscount `json:"discount" binding:"omitempty"`
+}
+
+// CheckoutPayment represents a single tender line in a checkout request
+// For cash, Amount is the cash handed over by the customer; for other methods
+// it is the exact amount charged to that method
+type CheckoutPayment struct {
+	Method    string      `json:"method" binding:"required,oneof=cash card qris ewallet voucher"`
+	Amount    money.Money `json:"amount" binding:"required,gt=0"`
+	Reference string      `json:"reference" binding:"max=100"`
 }
 
 // CheckoutRequest represents the checkout request payload
+// PromoCode and Discount apply to the whole order; item-level manual discounts
+// are given on the individual items. Without Payments the sale is settled with
+// exact cash, as it was before split tender existed
 type CheckoutRequest struct {
-	Items []CheckoutItem `json:"items" binding:"required,min=1"`
+	Items     []CheckoutItem    `json:"items" binding:"required,min=1,dive"`
+	Payments  []CheckoutPayment `json:"payments" binding:"omitempty,dive"`
+	PromoCode string            `json:"promo_code" binding:"max=50"`
+	Discount  *ManualDiscount   `json:"discount" binding:"omitempty"`
 }
 
 // CheckoutResponse represents the checkout response payload
 type CheckoutResponse struct {
-	TransactionID uint                    `json:"transaction_id"`
-	TotalAmount   float64                 `json:"total_amount"`
-	Items         []CheckoutItemResponse  `json:"items"`
+	TransactionID  uint                        `json:"transaction_id"`
+	Subtotal       money.Money                 `json:"subtotal"`
+	DiscountAmount money.Money                 `json:"discount_amount"`
+	ServiceCharge  money.Money                 `json:"service_charge"`
+	TaxAmount      money.Money                 `json:"tax_amount"`
+	TaxInclusive   bool                        `json:"tax_inclusive"`
+	TotalAmount    money.Money                 `json:"total_amount"`
+	Items          []CheckoutItemResponse      `json:"items"`
+	Discounts      []model.TransactionDiscount `json:"discounts"`
+	Payments       []model.Payment             `json:"payments"`
+	PaidAmount     money.Money                 `json:"paid_amount"`
+	ChangeAmount   money.Money                 `json:"change_amount"`
+	Replayed       bool                        `json:"replayed"`
 }
 
 // CheckoutItemResponse represents a single item in the checkout response
 type CheckoutItemResponse struct {
-	MenuID   uint    `json:"menu_id"`
-	Qty      int     `json:"qty"`
-	Subtotal float64 `json:"subtotal"`
+	MenuID         uint                              `json:"menu_id"`
+	MenuName       string                            `json:"menu_name"`
+	UnitPrice      money.Money                       `json:"unit_price"`
+	Qty            int                               `json:"qty"`
+	Subtotal       money.Money                       `json:"subtotal"`
+	DiscountAmount money.Money                       `json:"discount_amount"`
+	TaxAmount      money.Money                       `json:"tax_amount"`
+	LineTotal      money.Money                       `json:"line_total"`
+	Modifiers      []model.TransactionDetailModifier `json:"modifiers,omitempty"`
 }
 
-// ProcessedItem represents a processed checkout item from a goroutine
+// ProcessedItem represents a processed checkout item
+// UnitPrice is the menu price plus the price deltas of the chosen modifiers
+// Ingredients is the usage of the whole line for menu items and modifiers with a recipe
 type ProcessedItem struct {
-	MenuID   uint
-	Qty      int
-	Subtotal float64
-	Menu     *model.Menu
-	Error    error
+	MenuID      uint
+	Qty         int
+	UnitPrice   money.Money
+	Subtotal    money.Money
+	Menu        *model.Menu
+	Modifiers   []model.TransactionDetailModifier
+	Ingredients ingredientUsage
+	Error       error
 }
 
 // Checkout processes a checkout request sequentially within a database transaction
-func (s *TransactionService) Checkout(cashierID uint, req *CheckoutRequest) (*CheckoutResponse, error) {
+// When idempotencyKey is set, a retried request with the same key and payload returns
+// the original transaction instead of charging again
+// role determines the manual discount limit of the cashier
+func (s *TransactionService) Checkout(cashierID uint, role string, idempotencyKey string, req *CheckoutRequest) (*CheckoutResponse, error) {
+	var requestHash string
+	if idempotencyKey != "" {
+		var err error
+		requestHash, err = hashCheckoutRequest(req)
+		if err != nil {
+			return nil, err
+		}
+
+		// Answer retries from the stored transaction
+		response, err := s.findIdempotentCheckout(cashierID, idempotencyKey, requestHash)
+		if err == nil || !errors.Is(err, gorm.ErrRecordNotFound) {
+			return response, err
+		}
+	}
+
 	// Start a database transaction
 	tx := s.transactionRepo.BeginTransaction()
 	defer func() {
@@ -67,13 +154,24 @@ func (s *TransactionService) Checkout(cashierID uint, req *CheckoutRequest) (*Ch
 		}
 	}()
 
+	// Lock the menu items of the order in ID order before reading them
+	if err := s.menuRepo.LockByIDs(tx, checkoutMenuIDs(req.Items)); err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to lock menu items: %w", err)
+	}
+
 	// Process each item sequentially
+	// The same menu item may appear on several lines (for example with different
+	// modifiers), so stock is validated against the quantity already taken.
+	// Items made from a recipe take ingredients instead of their own stock
 	var processedItems []ProcessedItem
-	var totalAmount float64
+	var lines []*discountLine
+	reserved := make(map[uint]int)
+	ingredientsNeeded := make(ingredientUsage)
 
 	for _, item := range req.Items {
 		// Process the item
-		processedItem := s.processCheckoutItem(tx, item)
+		processedItem := s.processCheckoutItem(tx, item, reserved[item.MenuID])
 
 		// Check for errors
 		if processedItem.Error != nil {
@@ -81,48 +179,130 @@ func (s *TransactionService) Checkout(cashierID uint, req *CheckoutRequest) (*Ch
 			return nil, processedItem.Error
 		}
 
+		if !processedItem.Menu.HasRecipe() {
+			reserved[item.MenuID] += item.Qty
+		}
+		for ingredientID, quantity := range processedItem.Ingredients {
+			ingredientsNeeded.add(ingredientID, quantity, 1)
+		}
 		processedItems = append(processedItems, processedItem)
-		totalAmount += processedItem.Subtotal
+		lines = append(lines, &discountLine{
+			MenuID:    processedItem.MenuID,
+			UnitPrice: processedItem.UnitPrice,
+			Qty:       processedItem.Qty,
+			Gross:     processedItem.Subtotal,
+		})
+	}
+
+	// Lock the ingredients of the order and make sure there is enough of each
+	ingredients, err := s.reserveIngredients(tx, ingredientsNeeded)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Apply manual discounts and the promo code
+	appliedDiscounts, err := s.applyDiscounts(tx, role, req, lines)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Tax and service charge are calculated on the discounted amounts
+	var grossSubtotal money.Money
+	taxableLines := make([]TaxableLine, len(lines))
+	for i, line := range lines {
+		grossSubtotal = grossSubtotal.Add(line.Gross)
+		taxableLines[i] = TaxableLine{
+			Amount:    line.net(),
+			TaxExempt: processedItems[i].Menu.TaxExempt,
+		}
+	}
+
+	// Apply tax and service charge rules
+	breakdown := s.taxRules.Calculate(taxableLines)
+	totalAmount := breakdown.GrandTotal
+
+	// Settle the total with the requested payment lines
+	payments, changeAmount, err := allocatePayments(totalAmount, req.Payments)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
 	}
 
 	// Create the transaction record
 	transaction := &model.Transaction{
-		CashierID:   cashierID,
-		TotalAmount: totalAmount,
+		CashierID:         cashierID,
+		OutletCode:        s.outletCode,
+		Subtotal:          grossSubtotal,
+		DiscountAmount:    grossSubtotal.Sub(breakdown.Subtotal),
+		ServiceCharge:     breakdown.ServiceCharge,
+		TaxAmount:         breakdown.TaxAmount,
+		TotalAmount:       totalAmount,
+		TaxRate:           s.taxRules.TaxRate,
+		TaxInclusive:      s.taxRules.TaxInclusive,
+		ServiceChargeRate: s.taxRules.ServiceChargeRate,
+		PaidAmount:        totalAmount.Add(changeAmount),
+		ChangeAmount:      changeAmount,
+		RequestHash:       requestHash,
+	}
+	if idempotencyKey != "" {
+		transaction.IdempotencyKey = &idempotencyKey
 	}
 
-	err := s.transactionRepo.Create(tx, transaction)
+	err = s.transactionRepo.Create(tx, transaction)
 	if err != nil {
 		tx.Rollback()
+		// A concurrent retry with the same key committed first
+		if idempotencyKey != "" && errors.Is(err, gorm.ErrDuplicatedKey) {
+			return s.findIdempotentCheckout(cashierID, idempotencyKey, requestHash)
+		}
 		return nil, fmt.Errorf("failed to create transaction: %w", err)
 	}
 
 	// Create transaction details
 	var details []model.TransactionDetail
-	var responseItems []CheckoutItemResponse
+	var stockAlerts []StockAlert
+	costs := lineCosts(processedItems, ingredients)
 
-	for _, item := range processedItems {
+	for i, item := range processedItems {
+		lineTax := breakdown.Lines[i]
 		detail := model.TransactionDetail{
-			TransactionID: transaction.ID,
-			MenuID:        item.MenuID,
-			Qty:           item.Qty,
-			Subtotal:      item.Subtotal,
+			TransactionID:  transaction.ID,
+			MenuID:         item.MenuID,
+			MenuName:       item.Menu.Name,
+			UnitPrice:      item.UnitPrice,
+			Qty:            item.Qty,
+			Subtotal:       item.Subtotal,
+			UnitCost:       costs[i].Prorate(1, item.Qty),
+			CostAmount:     costs[i],
+			DiscountAmount: lines[i].Discount,
+			TaxExempt:      item.Menu.TaxExempt,
+			ServiceCharge:  lineTax.ServiceCharge,
+			TaxAmount:      lineTax.TaxAmount,
+			LineTotal:      lineTax.Total,
 		}
 		details = append(details, detail)
 
-		responseItems = append(responseItems, CheckoutItemResponse{
-			MenuID:   item.MenuID,
-			Qty:      item.Qty,
-			Subtotal: item.Subtotal,
-		})
-
-		// Update stock for each item
-		newStock := item.Menu.Stock - item.Qty
-		err = s.menuRepo.UpdateStock(tx, item.MenuID, newStock)
+		// Update stock once per menu item with the quantity of all its lines
+		if reserved[item.MenuID] == 0 {
+			continue
+		}
+		movement := &model.StockMovement{
+			Type:          model.StockMovementSale,
+			Quantity:      -reserved[item.MenuID],
+			UserID:        &cashierID,
+			TransactionID: &transaction.ID,
+		}
+		err = s.menuRepo.ApplyStockMovement(tx, item.Menu, movement)
 		if err != nil {
 			tx.Rollback()
 			return nil, fmt.Errorf("failed to update stock: %w", err)
 		}
+		if alert, ok := stockAlertFor(item.Menu, movement); ok {
+			stockAlerts = append(stockAlerts, alert)
+		}
+		reserved[item.MenuID] = 0
 	}
 
 	// Save all transaction details
@@ -132,22 +312,271 @@ func (s *TransactionService) Checkout(cashierID uint, req *CheckoutRequest) (*Ch
 		return nil, fmt.Errorf("failed to create transaction details: %w", err)
 	}
 
+	// Deduct ingredients per detail so a void can return what each line used
+	for i, item := range processedItems {
+		for _, ingredientID := range item.Ingredients.ids() {
+			movement := &model.IngredientMovement{
+				Type:                model.StockMovementSale,
+				Quantity:            -item.Ingredients[ingredientID],
+				UserID:              &cashierID,
+				TransactionID:       &transaction.ID,
+				TransactionDetailID: &details[i].ID,
+			}
+			ingredient := ingredients[ingredientID]
+			if err := s.ingredientRepo.ApplyMovement(tx, ingredient, movement); err != nil {
+				tx.Rollback()
+				return nil, fmt.Errorf("failed to update ingredient stock: %w", err)
+			}
+			if alert, ok := ingredientStockAlertFor(ingredient, movement); ok {
+				stockAlerts = append(stockAlerts, alert)
+			}
+		}
+	}
+
+	// Record the modifiers chosen for each detail
+	var modifiers []model.TransactionDetailModifier
+	for i, item := range processedItems {
+		for _, modifier := range item.Modifiers {
+			modifier.TransactionDetailID = details[i].ID
+			modifiers = append(modifiers, modifier)
+		}
+	}
+	err = s.transactionRepo.CreateDetailModifiers(tx, modifiers)
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to record modifiers: %w", err)
+	}
+	offset := 0
+	for i, item := range processedItems {
+		details[i].Modifiers = modifiers[offset : offset+len(item.Modifiers)]
+		offset += len(item.Modifiers)
+	}
+
+	// Record applied discounts against the transaction and its details
+	discounts := make([]model.TransactionDiscount, 0, len(appliedDiscounts))
+	for _, applied := range appliedDiscounts {
+		discount := model.TransactionDiscount{
+			TransactionID: transaction.ID,
+			PromotionID:   applied.PromotionID,
+			Source:        applied.Source,
+			Code:          applied.Code,
+			Description:   applied.Description,
+			Amount:        applied.Amount,
+		}
+		if applied.LineIndex >= 0 {
+			discount.TransactionDetailID = &details[applied.LineIndex].ID
+		}
+		discounts = append(discounts, discount)
+	}
+	err = s.transactionRepo.CreateDiscounts(tx, discounts)
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to record discounts: %w", err)
+	}
+
+	// Save all payment lines
+	for i := range payments {
+		payments[i].TransactionID = transaction.ID
+	}
+	err = s.transactionRepo.CreatePayments(tx, payments)
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to create payments: %w", err)
+	}
+
 	// Commit the transaction
 	if err := tx.Commit().Error; err != nil {
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
+	// Alert only about committed stock changes
+	if len(stockAlerts) > 0 {
+		s.stockNotifier.NotifyStockAlerts(stockAlerts)
+	}
+
 	// Return successful response
+	transaction.Details = details
+	transaction.Discounts = discounts
+	transaction.Payments = payments
+	return newCheckoutResponse(transaction), nil
+}
+
+// applyDiscounts applies item-level manual discounts, then the promo code, then the
+// order-level manual discount to the checkout lines
+// The promotion row is locked so usage limits hold under concurrent checkouts
+// The manual discounts together must stay within the role limit
+func (s *TransactionService) applyDiscounts(tx *gorm.DB, role string, req *CheckoutRequest, lines []*discountLine) ([]appliedDiscount, error) {
+	var applied []appliedDiscount
+	grossSubtotal := netTotal(lines)
+
+	for i, item := range req.Items {
+		if item.Discount == nil {
+			continue
+		}
+		applied = append(applied, applyManualDiscount(lines[i:i+1], i, item.Discount))
+	}
+
+	if code := strings.ToUpper(strings.TrimSpace(req.PromoCode)); code != "" {
+		promotion, err := s.promotionRepo.FindByCodeWithLock(tx, code)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrPromotionNotFound
+			}
+			return nil, fmt.Errorf("failed to fetch promotion: %w", err)
+		}
+
+		discounts, err := applyPromotion(lines, promotion, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		if err := s.promotionRepo.IncrementUsage(tx, promotion.ID); err != nil {
+			return nil, fmt.Errorf("failed to update promotion usage: %w", err)
+		}
+		applied = append(applied, discounts...)
+	}
+
+	if req.Discount != nil {
+		applied = append(applied, applyManualDiscount(lines, -1, req.Discount))
+	}
+
+	if err := checkManualDiscountLimit(applied, grossSubtotal, s.discountPolicy.MaxManualPercent[role]); err != nil {
+		return nil, err
+	}
+
+	return applied, nil
+}
+
+// findIdempotentCheckout returns the response of a previous checkout made with the same key
+// It returns gorm.ErrRecordNotFound when the key has not been used yet
+func (s *TransactionService) findIdempotentCheckout(cashierID uint, key, requestHash string) (*CheckoutResponse, error) {
+	existing, err := s.transactionRepo.FindByIdempotencyKey(cashierID, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing.RequestHash != requestHash {
+		return nil, ErrIdempotencyKeyConflict
+	}
+
+	response := newCheckoutResponse(existing)
+	response.Replayed = true
+	return response, nil
+}
+
+// newCheckoutResponse builds the checkout response from a persisted transaction
+func newCheckoutResponse(transaction *model.Transaction) *CheckoutResponse {
+	items := make([]CheckoutItemResponse, 0, len(transaction.Details))
+	for _, detail := range transaction.Details {
+		items = append(items, CheckoutItemResponse{
+			MenuID:         detail.MenuID,
+			MenuName:       detail.MenuName,
+			UnitPrice:      detail.UnitPrice,
+			Qty:            detail.Qty,
+			Subtotal:       detail.Subtotal,
+			DiscountAmount: detail.DiscountAmount,
+			TaxAmount:      detail.TaxAmount,
+			LineTotal:      detail.LineTotal,
+			Modifiers:      detail.Modifiers,
+		})
+	}
+
 	return &CheckoutResponse{
-		TransactionID: transaction.ID,
-		TotalAmount:   totalAmount,
-		Items:         responseItems,
-	}, nil
+		TransactionID:  transaction.ID,
+		Subtotal:       transaction.Subtotal,
+		DiscountAmount: transaction.DiscountAmount,
+		ServiceCharge:  transaction.ServiceCharge,
+		TaxAmount:      transaction.TaxAmount,
+		TaxInclusive:   transaction.TaxInclusive,
+		TotalAmount:    transaction.TotalAmount,
+		Items:          items,
+		Discounts:      transaction.Discounts,
+		Payments:       transaction.Payments,
+		PaidAmount:     transaction.PaidAmount,
+		ChangeAmount:   transaction.ChangeAmount,
+	}
+}
+
+// allocatePayments settles total with the requested tender lines and returns the change due
+// Non-cash lines are charged exactly and together may not exceed the total. At most one
+// cash line is allowed; it covers the remainder and must be at least that amount, with
+// the excess returned as change. Without any lines the total is paid in exact cash
+func allocatePayments(total money.Money, lines []CheckoutPayment) ([]model.Payment, money.Money, error) {
+	if len(lines) == 0 {
+		return []model.Payment{{Method: model.PaymentMethodCash, Amount: total, Tendered: total}}, 0, nil
+	}
+
+	var payments []model.Payment
+	var nonCash money.Money
+	cashIndex := -1
+
+	for _, line := range lines {
+		payment := model.Payment{
+			Method:    line.Method,
+			Amount:    line.Amount,
+			Tendered:  line.Amount,
+			Reference: strings.TrimSpace(line.Reference),
+		}
+
+		if line.Method == model.PaymentMethodCash {
+			if cashIndex >= 0 {
+				return nil, 0, fmt.Errorf("%w: only one cash payment line is allowed", ErrInvalidPayment)
+			}
+			cashIndex = len(payments)
+		} else {
+			nonCash = nonCash.Add(line.Amount)
+		}
+		payments = append(payments, payment)
+	}
+
+	if nonCash > total {
+		return nil, 0, fmt.Errorf("%w: non-cash payments (%s) exceed the total (%s)", ErrInvalidPayment, nonCash, total)
+	}
+
+	remaining := total.Sub(nonCash)
+	if cashIndex < 0 {
+		if !remaining.IsZero() {
+			return nil, 0, fmt.Errorf("%w: payments (%s) do not cover the total (%s)", ErrInvalidPayment, nonCash, total)
+		}
+		return payments, 0, nil
+	}
+
+	cash := &payments[cashIndex]
+	if cash.Tendered < remaining {
+		return nil, 0, fmt.Errorf("%w: cash tendered (%s) is less than the amount due (%s)", ErrInvalidPayment, cash.Tendered, remaining)
+	}
+
+	cash.Amount = remaining
+	cash.Change = cash.Tendered.Sub(remaining)
+	return payments, cash.Change, nil
+}
+
+// hashCheckoutRequest returns a stable SHA-256 fingerprint of a checkout payload
+func hashCheckoutRequest(req *CheckoutRequest) (string, error) {
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash checkout request: %w", err)
+	}
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// checkoutMenuIDs returns the distinct menu IDs of the checkout items in ascending order
+func checkoutMenuIDs(items []CheckoutItem) []uint {
+	seen := make(map[uint]bool, len(items))
+	ids := make([]uint, 0, len(items))
+	for _, item := range items {
+		if !seen[item.MenuID] {
+			seen[item.MenuID] = true
+			ids = append(ids, item.MenuID)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
 }
 
 // processCheckoutItem processes a single checkout item
-// This function is called within a goroutine for concurrent processing
-func (s *TransactionService) processCheckoutItem(tx *gorm.DB, item CheckoutItem) ProcessedItem {
+// reserved is the quantity of the same menu item taken by earlier lines of the order
+func (s *TransactionService) processCheckoutItem(tx *gorm.DB, item CheckoutItem, reserved int) ProcessedItem {
 	// Fetch menu item with row-level lock to prevent race conditions
 	menu, err := s.menuRepo.FindByIDWithLock(tx, item.MenuID)
 	if err != nil {
@@ -161,38 +590,253 @@ func (s *TransactionService) processCheckoutItem(tx *gorm.DB, item CheckoutItem)
 		}
 	}
 
+	// Disabled items are off sale whatever their stock
+	if menu.Disabled {
+		return ProcessedItem{
+			Error: fmt.Errorf("menu item '%s' is currently unavailable", menu.Name),
+		}
+	}
+
+	// Items with a recipe are checked against their ingredients once the whole order is known
+	menu.Recipe, err = s.ingredientRepo.GetMenuRecipe(tx, menu.ID)
+	if err != nil {
+		return ProcessedItem{
+			Error: fmt.Errorf("failed to fetch recipe: %w", err),
+		}
+	}
+
 	// Validate stock availability
-	if menu.Stock < item.Qty {
+	if available := menu.Stock - reserved; !menu.HasRecipe() && available < item.Qty {
 		return ProcessedItem{
 			Error: fmt.Errorf("insufficient stock for menu item '%s' (available: %d, requested: %d)",
-				menu.Name, menu.Stock, item.Qty),
+				menu.Name, available, item.Qty),
 		}
 	}
 
-	// Calculate subtotal
-	subtotal := menu.Price * float64(item.Qty)
+	// Validate the chosen modifiers and price them
+	groups, err := s.modifierRepo.GetActiveByMenuID(tx, menu.ID)
+	if err != nil {
+		return ProcessedItem{
+			Error: fmt.Errorf("failed to fetch modifiers: %w", err),
+		}
+	}
+	modifiers, delta, err := resolveModifiers(menu, groups, item.ModifierOptionIDs)
+	if err != nil {
+		return ProcessedItem{Error: err}
+	}
+
+	// Ingredients used by the item's recipe and by the chosen modifiers
+	optionIDs := make([]uint, len(modifiers))
+	for i, modifier := range modifiers {
+		optionIDs[i] = modifier.ModifierOptionID
+	}
+	optionRecipes, err := s.ingredientRepo.GetOptionRecipes(tx, optionIDs)
+	if err != nil {
+		return ProcessedItem{
+			Error: fmt.Errorf("failed to fetch modifier recipes: %w", err),
+		}
+	}
+	usage := make(ingredientUsage)
+	for _, line := range menu.Recipe {
+		usage.add(line.IngredientID, line.Quantity, item.Qty)
+	}
+	for _, line := range optionRecipes {
+		usage.add(line.IngredientID, line.Quantity, item.Qty)
+	}
+
+	// Calculate subtotal in exact minor units
+	unitPrice := menu.Price.Add(delta)
+	subtotal := unitPrice.Mul(item.Qty)
 
 	// Return processed item
 	return ProcessedItem{
-		MenuID:   item.MenuID,
-		Qty:      item.Qty,
-		Subtotal: subtotal,
-		Menu:     menu,
-		Error:    nil,
+		MenuID:      item.MenuID,
+		Qty:         item.Qty,
+		UnitPrice:   unitPrice,
+		Subtotal:    subtotal,
+		Menu:        menu,
+		Modifiers:   modifiers,
+		Ingredients: usage,
+		Error:       nil,
+	}
+}
+
+// lineCosts returns the cost of goods of each processed item: the menu item's
+// CostPrice for items with their own stock, plus the ingredients of its recipe
+// and modifiers at weighted average cost
+// Ingredients are costed in the order checkout deducts them, so each line's
+// cost matches the stock value its ingredient movements remove
+func lineCosts(items []ProcessedItem, ingredients map[uint]*model.Ingredient) []money.Money {
+	remaining := make(map[uint]model.Ingredient, len(ingredients))
+	for id, ingredient := range ingredients {
+		remaining[id] = *ingredient
+	}
+
+	costs := make([]money.Money, len(items))
+	for i, item := range items {
+		if !item.Menu.HasRecipe() {
+			costs[i] = item.Menu.CostPrice.Mul(item.Qty)
+		}
+		for _, ingredientID := range item.Ingredients.ids() {
+			ingredient := remaining[ingredientID]
+			quantity := item.Ingredients[ingredientID]
+			costs[i] = costs[i].Add(ingredient.CostOf(quantity))
+			ingredient.StockValue = ingredient.StockValueAfter(-quantity, nil)
+			ingredient.Stock -= quantity
+			remaining[ingredientID] = ingredient
+		}
+	}
+	return costs
+}
+
+// reserveIngredients locks the ingredients an order needs, in ID order, and
+// checks that each has enough stock for the whole order
+func (s *TransactionService) reserveIngredients(tx *gorm.DB, needed ingredientUsage) (map[uint]*model.Ingredient, error) {
+	locked, err := s.ingredientRepo.FindByIDsWithLock(tx, needed.ids())
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch ingredients: %w", err)
+	}
+	if len(locked) != len(needed) {
+		return nil, errors.New("an ingredient of this order no longer exists")
+	}
+
+	ingredients := make(map[uint]*model.Ingredient, len(locked))
+	for i := range locked {
+		ingredient := &locked[i]
+		if ingredient.Stock < needed[ingredient.ID] {
+			return nil, fmt.Errorf("insufficient %s for this order (available: %d %s, required: %d %s)",
+				ingredient.Name, ingredient.Stock, ingredient.Unit, needed[ingredient.ID], ingredient.Unit)
+		}
+		ingredients[ingredient.ID] = ingredient
+	}
+	return ingredients, nil
+}
+
+// TransactionListQuery represents the query parameters of the transaction history
+// From and To accept a date (2006-01-02) or an RFC 3339 timestamp; a date in To
+// includes the whole day. Amounts are decimal strings compared with the grand total
+type TransactionListQuery struct {
+	From          string `form:"from"`
+	To            string `form:"to"`
+	MinAmount     string `form:"min_amount"`
+	MaxAmount     string `form:"max_amount"`
+	PaymentMethod string `form:"payment_method" binding:"omitempty,oneof=cash card qris ewallet voucher"`
+	Status        string `form:"status" binding:"omitempty,oneof=completed partially_refunded refunded voided"`
+	Cursor        uint   `form:"cursor"`
+	Limit         int    `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+// DefaultTransactionPageSize is the page size used when no limit is given
+const DefaultTransactionPageSize = 20
+
+// TransactionList is a page of transactions, newest first
+// NextCursor is the cursor of the following page and is nil on the last page;
+// Totals is only set for listings that aggregate the matching transactions
+type TransactionList struct {
+	Transactions []model.Transaction
+	Limit        int
+	NextCursor   *uint
+	Totals       *repository.TransactionTotals
+}
+
+// ListTransactions retrieves a page of a cashier's transactions matching the query
+func (s *TransactionService) ListTransactions(cashierID uint, query *TransactionListQuery) (*TransactionList, error) {
+	filter, err := newTransactionFilter(query)
+	if err != nil {
+		return nil, err
 	}
+	filter.CashierID = &cashierID
+
+	return s.listTransactions(filter)
 }
 
-// GetTransactionsByCashier retrieves all transactions for a specific cashier
-func (s *TransactionService) GetTransactionsByCashier(cashierID uint) ([]model.Transaction, error) {
-	return s.transactionRepo.GetByCashierID(cashierID)
+// listTransactions retrieves one page of transactions for the filter
+func (s *TransactionService) listTransactions(filter repository.TransactionFilter) (*TransactionList, error) {
+	// Fetch one extra row to know whether another page follows
+	limit := filter.Limit
+	filter.Limit = limit + 1
+
+	transactions, err := s.transactionRepo.List(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	list := &TransactionList{Transactions: transactions, Limit: limit}
+	if len(transactions) > limit {
+		list.Transactions = transactions[:limit]
+		next := list.Transactions[limit-1].ID
+		list.NextCursor = &next
+	}
+	return list, nil
+}
+
+// newTransactionFilter converts the history query parameters into a repository filter
+func newTransactionFilter(query *TransactionListQuery) (repository.TransactionFilter, error) {
+	filter := repository.TransactionFilter{
+		PaymentMethod: query.PaymentMethod,
+		Status:        query.Status,
+		Cursor:        query.Cursor,
+		Limit:         query.Limit,
+	}
+	if filter.Limit == 0 {
+		filter.Limit = DefaultTransactionPageSize
+	}
+
+	var err error
+	if filter.From, filter.To, err = parseQueryTimeRange(query.From, query.To); err != nil {
+		return filter, fmt.Errorf("%w: %v", ErrInvalidTransactionQuery, err)
+	}
+
+	if filter.MinAmount, err = parseQueryAmount("min_amount", query.MinAmount); err != nil {
+		return filter, fmt.Errorf("%w: %v", ErrInvalidTransactionQuery, err)
+	}
+	if filter.MaxAmount, err = parseQueryAmount("max_amount", query.MaxAmount); err != nil {
+		return filter, fmt.Errorf("%w: %v", ErrInvalidTransactionQuery, err)
+	}
+	if filter.MinAmount != nil && filter.MaxAmount != nil && *filter.MinAmount > *filter.MaxAmount {
+		return filter, fmt.Errorf("%w: min_amount cannot exceed max_amount", ErrInvalidTransactionQuery)
+	}
+
+	return filter, nil
 }
 
 // GetTransactionByID retrieves a transaction by ID
 func (s *TransactionService) GetTransactionByID(id uint) (*model.Transaction, error) {
-	return s.transactionRepo.FindByID(id)
+	transaction, err := s.transactionRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTransactionNotFound
+		}
+		return nil, err
+	}
+	return transaction, nil
+}
+
+// AllTransactionsQuery represents the query parameters of the cross-cashier transaction listing
+type AllTransactionsQuery struct {
+	TransactionListQuery
+	CashierID *uint `form:"cashier_id"`
 }
 
-// GetAllTransactions retrieves all transactions (admin function)
-func (s *TransactionService) GetAllTransactions() ([]model.Transaction, error) {
-	return s.transactionRepo.GetAll()
+// GetAllTransactions retrieves a page of transactions across all cashiers with the
+// cashier of each transaction and totals aggregated over every matching transaction
+func (s *TransactionService) GetAllTransactions(query *AllTransactionsQuery) (*TransactionList, error) {
+	filter, err := newTransactionFilter(&query.TransactionListQuery)
+	if err != nil {
+		return nil, err
+	}
+	filter.CashierID = query.CashierID
+
+	totals, err := s.transactionRepo.Totals(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	filter.IncludeCashier = true
+	list, err := s.listTransactions(filter)
+	if err != nil {
+		return nil, err
+	}
+	list.Totals = &totals
+	return list, nil
 }
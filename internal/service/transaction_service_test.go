@@ -0,0 +1,129 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"service-cashier/internal/model"
+	"service-cashier/pkg/money"
+	"testing"
+)
+
+func TestAllocatePayments(t *testing.T) {
+	tests := []struct {
+		name       string
+		total      money.Money
+		lines      []CheckoutPayment
+		want       []model.Payment
+		wantChange money.Money
+		wantErr    bool
+	}{
+		{
+			name:  "no payments defaults to exact cash",
+			total: 5000,
+			want:  []model.Payment{{Method: model.PaymentMethodCash, Amount: 5000, Tendered: 5000}},
+		},
+		{
+			name:  "exact cash",
+			total: 5000,
+			lines: []CheckoutPayment{{Method: model.PaymentMethodCash, Amount: 5000}},
+			want:  []model.Payment{{Method: model.PaymentMethodCash, Amount: 5000, Tendered: 5000}},
+		},
+		{
+			name:       "cash with change",
+			total:      4550,
+			lines:      []CheckoutPayment{{Method: model.PaymentMethodCash, Amount: 5000}},
+			want:       []model.Payment{{Method: model.PaymentMethodCash, Amount: 4550, Tendered: 5000, Change: 450}},
+			wantChange: 450,
+		},
+		{
+			name:  "exact card",
+			total: 10000,
+			lines: []CheckoutPayment{{Method: model.PaymentMethodCard, Amount: 10000, Reference: " AUTH-1 "}},
+			want:  []model.Payment{{Method: model.PaymentMethodCard, Amount: 10000, Tendered: 10000, Reference: "AUTH-1"}},
+		},
+		{
+			name:  "split card and cash",
+			total: 10000,
+			lines: []CheckoutPayment{
+				{Method: model.PaymentMethodCash, Amount: 5000},
+				{Method: model.PaymentMethodCard, Amount: 6000},
+			},
+			want: []model.Payment{
+				{Method: model.PaymentMethodCash, Amount: 4000, Tendered: 5000, Change: 1000},
+				{Method: model.PaymentMethodCard, Amount: 6000, Tendered: 6000},
+			},
+			wantChange: 1000,
+		},
+		{
+			name:  "split non-cash payments",
+			total: 10000,
+			lines: []CheckoutPayment{
+				{Method: model.PaymentMethodQRIS, Amount: 2500},
+				{Method: model.PaymentMethodVoucher, Amount: 7500},
+			},
+			want: []model.Payment{
+				{Method: model.PaymentMethodQRIS, Amount: 2500, Tendered: 2500},
+				{Method: model.PaymentMethodVoucher, Amount: 7500, Tendered: 7500},
+			},
+		},
+		{
+			name:    "non-cash short of the total",
+			total:   10000,
+			lines:   []CheckoutPayment{{Method: model.PaymentMethodCard, Amount: 9999}},
+			wantErr: true,
+		},
+		{
+			name:    "non-cash over the total",
+			total:   10000,
+			lines:   []CheckoutPayment{{Method: model.PaymentMethodCard, Amount: 10001}},
+			wantErr: true,
+		},
+		{
+			name:  "cash short of the amount due",
+			total: 10000,
+			lines: []CheckoutPayment{
+				{Method: model.PaymentMethodCard, Amount: 3000},
+				{Method: model.PaymentMethodCash, Amount: 6999},
+			},
+			wantErr: true,
+		},
+		{
+			name:  "two cash lines",
+			total: 10000,
+			lines: []CheckoutPayment{
+				{Method: model.PaymentMethodCash, Amount: 5000},
+				{Method: model.PaymentMethodCash, Amount: 5000},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, change, err := allocatePayments(tt.total, tt.lines)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidPayment) {
+					t.Fatalf("error = %v, want ErrInvalidPayment", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("payments = %+v, want %+v", got, tt.want)
+			}
+			if change != tt.wantChange {
+				t.Errorf("change = %s, want %s", change, tt.wantChange)
+			}
+
+			var paid money.Money
+			for _, payment := range got {
+				paid = paid.Add(payment.Amount)
+			}
+			if paid != tt.total {
+				t.Errorf("payment amounts add up to %s, want %s", paid, tt.total)
+			}
+		})
+	}
+}
@@ -2,14 +2,32 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"log"
 	"service-cashier/internal/model"
 	"service-cashier/internal/repository"
 	"service-cashier/pkg/utils"
+	"strings"
 
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
+var (
+	// ErrUserNotFound is returned when the requested user does not exist
+	ErrUserNotFound = errors.New("user not found")
+	// ErrUsernameExists is returned when the username is already taken
+	ErrUsernameExists = errors.New("username already exists")
+	// ErrInvalidRole is returned when an unknown role is requested
+	ErrInvalidRole = errors.New("invalid role")
+	// ErrUserDisabled is returned when a disabled user tries to log in
+	ErrUserDisabled = errors.New("user account is disabled")
+	// ErrCannotModifySelf is returned when an admin tries to disable, demote or delete their own account
+	ErrCannotModifySelf = errors.New("you cannot disable, demote or delete your own account")
+	// ErrLastAdmin is returned when an action would leave the system without an active admin
+	ErrLastAdmin = errors.New("at least one active admin account is required")
+)
+
 // UserService handles user business logic
 type UserService struct {
 	userRepo  *repository.UserRepository
@@ -32,7 +50,32 @@ type LoginRequest struct {
 
 // LoginResponse represents the login response payload
 type LoginResponse struct {
-	Token string `json:"token"`
+	Token       string   `json:"token"`
+	Role        string   `json:"role"`
+	Permissions []string `json:"permissions"`
+}
+
+// Password length limits; bcrypt ignores everything past 72 bytes
+const (
+	minPasswordLength = 8
+	maxPasswordLength = 72
+)
+
+// CreateUserRequest represents the create user payload
+type CreateUserRequest struct {
+	Username string `json:"username" binding:"required,min=3,max=50"`
+	Password string `json:"password" binding:"required,min=8,max=72"`
+	Role     string `json:"role" binding:"omitempty,oneof=cashier supervisor admin"`
+}
+
+// ResetPasswordRequest represents the reset password payload
+type ResetPasswordRequest struct {
+	Password string `json:"password" binding:"required,min=8,max=72"`
+}
+
+// UpdateRoleRequest represents the change role payload
+type UpdateRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=cashier supervisor admin"`
 }
 
 // Login authenticates a user and returns a JWT token
@@ -52,33 +95,58 @@ func (s *UserService) Login(req *LoginRequest) (*LoginResponse, error) {
 		return nil, errors.New("invalid username or password")
 	}
 
+	// Disabled accounts cannot obtain new tokens
+	if !user.Active {
+		return nil, ErrUserDisabled
+	}
+
 	// Generate JWT token
-	token, err := utils.GenerateToken(user.ID, user.Username, s.jwtSecret)
+	token, err := utils.GenerateToken(user.ID, user.Username, user.Role, s.jwtSecret)
 	if err != nil {
 		return nil, errors.New("failed to generate token")
 	}
 
-	return &LoginResponse{Token: token}, nil
+	return &LoginResponse{
+		Token:       token,
+		Role:        user.Role,
+		Permissions: model.PermissionsForRole(user.Role),
+	}, nil
 }
 
 // CreateUser creates a new user with hashed password
-func (s *UserService) CreateUser(username, password string) (*model.User, error) {
-	// Check if user already exists
-	existingUser, _ := s.userRepo.FindByUsername(username)
-	if existingUser != nil {
-		return nil, errors.New("username already exists")
+// The role defaults to cashier when not provided
+func (s *UserService) CreateUser(req *CreateUserRequest) (*model.User, error) {
+	username := strings.TrimSpace(req.Username)
+
+	role := req.Role
+	if role == "" {
+		role = model.RoleCashier
+	}
+	if !model.IsValidRole(role) {
+		return nil, ErrInvalidRole
+	}
+
+	// Check if user already exists; archived users keep their username
+	exists, err := s.userRepo.ExistsByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrUsernameExists
 	}
 
 	// Hash password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	hashedPassword, err := hashPassword(req.Password)
 	if err != nil {
-		return nil, errors.New("failed to hash password")
+		return nil, err
 	}
 
 	// Create user
 	user := &model.User{
 		Username:     username,
-		PasswordHash: string(hashedPassword),
+		PasswordHash: hashedPassword,
+		Role:         role,
+		Active:       true,
 	}
 
 	err = s.userRepo.Create(user)
@@ -89,7 +157,240 @@ func (s *UserService) CreateUser(username, password string) (*model.User, error)
 	return user, nil
 }
 
+// BootstrapAdmin creates the first admin account on an empty database
+// from ADMIN_USERNAME and ADMIN_PASSWORD. It is a no-op once any user exists,
+// so it is safe to call on every startup.
+//
+// As a recovery path, when users exist but none is an active admin and
+// ADMIN_PASSWORD is set, the ADMIN_USERNAME account is reset to that password
+// and promoted to an active admin (restoring it if archived), or created if
+// there is no such account
+func (s *UserService) BootstrapAdmin(username, password string) error {
+	users, err := s.userRepo.Count()
+	if err != nil {
+		return err
+	}
+	username = strings.TrimSpace(username)
+
+	if users == 0 {
+		if username == "" {
+			log.Println("WARNING: the users table is empty and ADMIN_USERNAME is not set; nobody will be able to log in")
+			return nil
+		}
+		if err := validateBootstrapPassword(password); err != nil {
+			return err
+		}
+		if _, err := s.CreateUser(&CreateUserRequest{Username: username, Password: password, Role: model.RoleAdmin}); err != nil {
+			return err
+		}
+		log.Printf("Bootstrapped initial admin account %q", username)
+		return nil
+	}
+
+	admins, err := s.userRepo.CountActiveByRole(model.RoleAdmin)
+	if err != nil {
+		return err
+	}
+	if admins > 0 {
+		return nil
+	}
+
+	if username == "" || password == "" {
+		log.Println("WARNING: no active admin account exists; set ADMIN_USERNAME and ADMIN_PASSWORD and restart to recover one")
+		return nil
+	}
+	if err := validateBootstrapPassword(password); err != nil {
+		return err
+	}
+	return s.recoverBootstrapAdmin(username, password)
+}
+
+// recoverBootstrapAdmin turns the named account into an active admin with the
+// given password, creating it if it does not exist
+func (s *UserService) recoverBootstrapAdmin(username, password string) error {
+	user, err := s.userRepo.FindByUsernameUnscoped(username)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		if _, err := s.CreateUser(&CreateUserRequest{Username: username, Password: password, Role: model.RoleAdmin}); err != nil {
+			return err
+		}
+		log.Printf("WARNING: no active admin account existed; created admin account %q from ADMIN_PASSWORD", username)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	hashedPassword, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	if user.DeletedAt.Valid {
+		if err := s.userRepo.Restore(user.ID); err != nil {
+			return err
+		}
+		user.DeletedAt = gorm.DeletedAt{}
+	}
+
+	user.PasswordHash = hashedPassword
+	user.Role = model.RoleAdmin
+	user.Active = true
+	if err := s.userRepo.Update(user); err != nil {
+		return err
+	}
+
+	log.Printf("WARNING: no active admin account existed; reset the password of %q from ADMIN_PASSWORD and promoted it to an active admin", user.Username)
+	return nil
+}
+
+// validateBootstrapPassword checks ADMIN_PASSWORD against the password rules
+func validateBootstrapPassword(password string) error {
+	if len(password) < minPasswordLength || len(password) > maxPasswordLength {
+		return fmt.Errorf("ADMIN_PASSWORD must be between %d and %d characters", minPasswordLength, maxPasswordLength)
+	}
+	return nil
+}
+
+// GetAllUsers retrieves all live users, or only archived users when archived is set
+func (s *UserService) GetAllUsers(archived bool) ([]model.User, error) {
+	return s.userRepo.GetAll(archived)
+}
+
 // GetUserByID retrieves a user by ID
 func (s *UserService) GetUserByID(id uint) (*model.User, error) {
-	return s.userRepo.FindByID(id)
+	user, err := s.userRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// SetUserActive enables or disables a user account
+func (s *UserService) SetUserActive(actorID, id uint, active bool) (*model.User, error) {
+	user, err := s.GetUserByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !active {
+		if err := s.ensureCanRemoveAdmin(actorID, user); err != nil {
+			return nil, err
+		}
+	}
+
+	user.Active = active
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// UpdateUserRole changes the role of a user
+func (s *UserService) UpdateUserRole(actorID, id uint, role string) (*model.User, error) {
+	if !model.IsValidRole(role) {
+		return nil, ErrInvalidRole
+	}
+
+	user, err := s.GetUserByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if role != model.RoleAdmin {
+		if err := s.ensureCanRemoveAdmin(actorID, user); err != nil {
+			return nil, err
+		}
+	}
+
+	user.Role = role
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// ResetPassword replaces the password of a user
+func (s *UserService) ResetPassword(id uint, password string) error {
+	user, err := s.GetUserByID(id)
+	if err != nil {
+		return err
+	}
+
+	hashedPassword, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	user.PasswordHash = hashedPassword
+	return s.userRepo.Update(user)
+}
+
+// DeleteUser archives a user by ID
+// Archived users cannot log in but remain the cashier of their past transactions
+func (s *UserService) DeleteUser(actorID, id uint) error {
+	user, err := s.GetUserByID(id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.ensureCanRemoveAdmin(actorID, user); err != nil {
+		return err
+	}
+
+	return s.userRepo.Delete(id)
+}
+
+// RestoreUser brings an archived user back
+// Restoring a user that is not archived is a no-op
+func (s *UserService) RestoreUser(id uint) (*model.User, error) {
+	user, err := s.userRepo.FindByIDUnscoped(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	if user.DeletedAt.Valid {
+		if err := s.userRepo.Restore(id); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.GetUserByID(id)
+}
+
+// ensureCanRemoveAdmin prevents users from locking themselves out and
+// prevents the last active admin from being disabled, demoted or deleted
+func (s *UserService) ensureCanRemoveAdmin(actorID uint, user *model.User) error {
+	if user.ID == actorID {
+		return ErrCannotModifySelf
+	}
+
+	if user.Role != model.RoleAdmin || !user.Active {
+		return nil
+	}
+
+	count, err := s.userRepo.CountActiveByRole(model.RoleAdmin)
+	if err != nil {
+		return err
+	}
+	if count <= 1 {
+		return ErrLastAdmin
+	}
+	return nil
+}
+
+// hashPassword hashes a plain text password with bcrypt
+func hashPassword(password string) (string, error) {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", errors.New("failed to hash password")
+	}
+	return string(hashedPassword), nil
 }
@@ -0,0 +1,235 @@
+package money
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Scale is the number of minor units in one major unit (two decimal places)
+const Scale = 100
+
+// Money is an exact monetary amount stored as integer minor units (cents, sen)
+//
+// It maps to decimal(10,2) columns and is encoded in JSON as a plain number with
+// exactly two decimal places, e.g. 25000.00. Arithmetic on Money is exact; the
+// only rounding happens when parsing input with more than two decimal places,
+// which is rounded half away from zero to the nearest minor unit.
+type Money int64
+
+// Zero is the zero amount
+const Zero Money = 0
+
+// ErrInvalidAmount is returned when a value cannot be parsed as a monetary amount
+var ErrInvalidAmount = errors.New("invalid monetary amount")
+
+// FromMinor creates an amount from integer minor units
+func FromMinor(minor int64) Money {
+	return Money(minor)
+}
+
+// FromMajor creates an amount from whole major units
+func FromMajor(major int64) Money {
+	return Money(major * Scale)
+}
+
+// Parse parses a decimal string such as "25000", "-1.5" or "29999.99"
+// Digits beyond the second decimal place are rounded half away from zero
+func Parse(s string) (Money, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, ErrInvalidAmount
+	}
+
+	negative := false
+	switch s[0] {
+	case '-':
+		negative = true
+		s = s[1:]
+	case '+':
+		s = s[1:]
+	}
+
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	if intPart == "" && fracPart == "" {
+		return 0, ErrInvalidAmount
+	}
+	if !isDigits(intPart) || !isDigits(fracPart) {
+		return 0, ErrInvalidAmount
+	}
+
+	var major int64
+	if intPart != "" {
+		var err error
+		major, err = strconv.ParseInt(intPart, 10, 64)
+		if err != nil || major > math.MaxInt64/Scale-1 {
+			return 0, ErrInvalidAmount
+		}
+	}
+
+	// Take the first two fractional digits and round on the third
+	padded := fracPart + "000"
+	minor, _ := strconv.ParseInt(padded[:2], 10, 64)
+	if padded[2] >= '5' {
+		minor++
+	}
+
+	amount := major*Scale + minor
+	if negative {
+		amount = -amount
+	}
+	return Money(amount), nil
+}
+
+// MustParse is like Parse but panics on invalid input
+// It is intended for constants and defaults
+func MustParse(s string) Money {
+	m, err := Parse(s)
+	if err != nil {
+		panic(fmt.Sprintf("money: cannot parse %q: %v", s, err))
+	}
+	return m
+}
+
+// Minor returns the amount in integer minor units
+func (m Money) Minor() int64 {
+	return int64(m)
+}
+
+// Add returns m + other
+func (m Money) Add(other Money) Money {
+	return m + other
+}
+
+// Sub returns m - other
+func (m Money) Sub(other Money) Money {
+	return m - other
+}
+
+// Mul returns the amount multiplied by an integer quantity
+func (m Money) Mul(qty int) Money {
+	return m * Money(qty)
+}
+
+// Prorate returns the share part/whole of the amount, rounded half away from zero
+// It is used to split a line amount across a subset of its quantity
+func (m Money) Prorate(part, whole int) Money {
+	if whole == 0 {
+		return 0
+	}
+	return Money(mulDivRound(int64(m), int64(part), int64(whole)))
+}
+
+// IsZero reports whether the amount is zero
+func (m Money) IsZero() bool {
+	return m == 0
+}
+
+// IsNegative reports whether the amount is below zero
+func (m Money) IsNegative() bool {
+	return m < 0
+}
+
+// String formats the amount with exactly two decimal places
+func (m Money) String() string {
+	minor := int64(m)
+	sign := ""
+	if minor < 0 {
+		sign = "-"
+		minor = -minor
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, minor/Scale, minor%Scale)
+}
+
+// MarshalJSON encodes the amount as a JSON number with two decimal places
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON decodes the amount from a JSON number or numeric string
+func (m *Money) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	s = strings.Trim(s, `"`)
+
+	parsed, err := Parse(s)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidAmount, string(data))
+	}
+	*m = parsed
+	return nil
+}
+
+// Value implements driver.Valuer so the amount is written as an exact decimal string
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+// Scan implements sql.Scanner for decimal columns
+func (m *Money) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*m = 0
+		return nil
+	case []byte:
+		parsed, err := Parse(string(v))
+		if err != nil {
+			return err
+		}
+		*m = parsed
+		return nil
+	case string:
+		parsed, err := Parse(v)
+		if err != nil {
+			return err
+		}
+		*m = parsed
+		return nil
+	case int64:
+		*m = FromMajor(v)
+		return nil
+	case float64:
+		parsed, err := Parse(strconv.FormatFloat(v, 'f', -1, 64))
+		if err != nil {
+			return err
+		}
+		*m = parsed
+		return nil
+	default:
+		return fmt.Errorf("money: cannot scan %T", src)
+	}
+}
+
+// divRound divides a by b rounding half away from zero
+func divRound(a, b int64) int64 {
+	if b < 0 {
+		a, b = -a, -b
+	}
+	q, r := a/b, a%b
+	if r < 0 {
+		r = -r
+	}
+	if 2*r >= b {
+		if a < 0 {
+			q--
+		} else {
+			q++
+		}
+	}
+	return q
+}
+
+// isDigits reports whether s consists only of ASCII digits
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
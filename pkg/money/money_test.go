@@ -0,0 +1,167 @@
+package money
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Money
+		wantErr bool
+	}{
+		{name: "whole amount", input: "25000", want: 2500000},
+		{name: "two decimals", input: "29999.99", want: 2999999},
+		{name: "one decimal", input: "-1.5", want: -150},
+		{name: "explicit plus sign", input: "+3", want: 300},
+		{name: "leading dot", input: ".5", want: 50},
+		{name: "trailing dot", input: "5.", want: 500},
+		{name: "surrounding spaces", input: " 12.34 ", want: 1234},
+		{name: "third decimal rounds up at five", input: "0.005", want: 1},
+		{name: "third decimal rounds down below five", input: "0.004", want: 0},
+		{name: "rounding carries into major units", input: "1.995", want: 200},
+		{name: "negative rounds away from zero", input: "-0.005", want: -1},
+		{name: "digits beyond the third are ignored", input: "0.0049", want: 0},
+		{name: "empty", input: "", wantErr: true},
+		{name: "sign only", input: "-", wantErr: true},
+		{name: "dot only", input: ".", wantErr: true},
+		{name: "letters", input: "abc", wantErr: true},
+		{name: "two dots", input: "1.2.3", wantErr: true},
+		{name: "exponent", input: "1e5", wantErr: true},
+		{name: "double sign", input: "--1", wantErr: true},
+		{name: "overflow", input: "99999999999999999999", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.input)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidAmount) {
+					t.Fatalf("Parse(%q) error = %v, want ErrInvalidAmount", tt.input, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse(%q) unexpected error: %v", tt.input, err)
+			}
+			if got != tt.want {
+				t.Errorf("Parse(%q) = %d, want %d", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		amount Money
+		want   string
+	}{
+		{amount: 0, want: "0.00"},
+		{amount: 5, want: "0.05"},
+		{amount: -5, want: "-0.05"},
+		{amount: -150, want: "-1.50"},
+		{amount: 2500000, want: "25000.00"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.amount.String(); got != tt.want {
+			t.Errorf("Money(%d).String() = %q, want %q", tt.amount, got, tt.want)
+		}
+	}
+}
+
+func TestProrate(t *testing.T) {
+	tests := []struct {
+		name   string
+		amount Money
+		part   int
+		whole  int
+		want   Money
+	}{
+		{name: "one third rounds down", amount: 1000, part: 1, whole: 3, want: 333},
+		{name: "two thirds rounds up", amount: 1000, part: 2, whole: 3, want: 667},
+		{name: "half rounds up", amount: 5, part: 1, whole: 2, want: 3},
+		{name: "negative half rounds away from zero", amount: -5, part: 1, whole: 2, want: -3},
+		{name: "whole share", amount: 999, part: 3, whole: 3, want: 999},
+		{name: "zero whole", amount: 1000, part: 1, whole: 0, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.amount.Prorate(tt.part, tt.whole); got != tt.want {
+				t.Errorf("Money(%d).Prorate(%d, %d) = %d, want %d", tt.amount, tt.part, tt.whole, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Money
+		wantErr bool
+	}{
+		{name: "number", input: `25000`, want: 2500000},
+		{name: "number with decimals", input: `12.5`, want: 1250},
+		{name: "numeric string", input: `"12.34"`, want: 1234},
+		{name: "null keeps the current value", input: `null`, want: 42},
+		{name: "invalid string", input: `"abc"`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Money(42)
+			err := got.UnmarshalJSON([]byte(tt.input))
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidAmount) {
+					t.Fatalf("UnmarshalJSON(%s) error = %v, want ErrInvalidAmount", tt.input, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UnmarshalJSON(%s) unexpected error: %v", tt.input, err)
+			}
+			if got != tt.want {
+				t.Errorf("UnmarshalJSON(%s) = %d, want %d", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScan(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     interface{}
+		want    Money
+		wantErr bool
+	}{
+		{name: "nil", src: nil, want: 0},
+		{name: "bytes", src: []byte("12.34"), want: 1234},
+		{name: "string", src: "-0.50", want: -50},
+		{name: "integer is major units", src: int64(5), want: 500},
+		{name: "float", src: float64(0.1), want: 10},
+		{name: "unsupported type", src: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Money
+			err := got.Scan(tt.src)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Scan(%v) expected an error", tt.src)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Scan(%v) unexpected error: %v", tt.src, err)
+			}
+			if got != tt.want {
+				t.Errorf("Scan(%v) = %d, want %d", tt.src, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package money
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Rate is a percentage with two decimal places stored in basis points (1% = 100)
+type Rate int64
+
+// rateScale is the number of basis points in 100%
+const rateScale = 10000
+
+// ParseRate parses a percentage such as "11", "5.5" or "0"
+// Digits beyond the second decimal place are rounded half away from zero
+func ParseRate(s string) (Rate, error) {
+	s = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(s), "%"))
+	if s == "" {
+		return 0, nil
+	}
+
+	// A percentage has the same two-decimal shape as an amount
+	m, err := Parse(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid rate %q", s)
+	}
+	if m < 0 {
+		return 0, fmt.Errorf("rate %q cannot be negative", s)
+	}
+	return Rate(m), nil
+}
+
+// IsZero reports whether the rate is zero
+func (r Rate) IsZero() bool {
+	return r == 0
+}
+
+// String formats the rate as a percentage with two decimal places, e.g. 11.00
+func (r Rate) String() string {
+	return Money(r).String()
+}
+
+// MarshalJSON encodes the rate as a JSON number with two decimal places
+func (r Rate) MarshalJSON() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalJSON decodes the rate from a JSON number or numeric string
+func (r *Rate) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "null" {
+		return nil
+	}
+	parsed, err := ParseRate(s)
+	if err != nil {
+		return err
+	}
+	*r = parsed
+	return nil
+}
+
+// Value implements driver.Valuer so the rate is stored as a decimal percentage
+func (r Rate) Value() (driver.Value, error) {
+	return r.String(), nil
+}
+
+// Scan implements sql.Scanner for decimal percentage columns
+func (r *Rate) Scan(src interface{}) error {
+	var m Money
+	if err := m.Scan(src); err != nil {
+		return err
+	}
+	*r = Rate(m)
+	return nil
+}
+
+// MulRate returns the amount multiplied by a percentage, rounded half away from zero
+func (m Money) MulRate(r Rate) Money {
+	return Money(mulDivRound(int64(m), int64(r), rateScale))
+}
+
+// RateOf returns part as a percentage of whole, rounded half away from zero
+// It is zero when whole is zero
+func RateOf(part, whole Money) Rate {
+	return Rate(mulDivRound(int64(part), rateScale, int64(whole)))
+}
+
+// IncludedTax returns the tax contained in a tax-inclusive amount,
+// i.e. m × r / (100% + r), rounded half away from zero
+func (m Money) IncludedTax(r Rate) Money {
+	return Money(mulDivRound(int64(m), int64(r), rateScale+int64(r)))
+}
+
+// Allocate splits total across weights proportionally using the largest remainder
+// method, so the parts always add up to exactly total
+func Allocate(total Money, weights []Money) []Money {
+	parts := make([]Money, len(weights))
+	if len(weights) == 0 {
+		return parts
+	}
+
+	var sum int64
+	for _, w := range weights {
+		sum += int64(w)
+	}
+	if sum == 0 {
+		parts[0] = total
+		return parts
+	}
+
+	type remainder struct {
+		index int
+		value *big.Int
+	}
+	remainders := make([]remainder, len(weights))
+	allocated := Money(0)
+	bigSum := big.NewInt(sum)
+
+	for i, w := range weights {
+		product := new(big.Int).Mul(big.NewInt(int64(total)), big.NewInt(int64(w)))
+		quotient, rem := new(big.Int).QuoRem(product, bigSum, new(big.Int))
+		parts[i] = Money(quotient.Int64())
+		allocated += parts[i]
+		remainders[i] = remainder{index: i, value: rem.Abs(rem)}
+	}
+
+	// Hand out the leftover minor units to the largest remainders first
+	leftover := total - allocated
+	step := Money(1)
+	if leftover < 0 {
+		step = -1
+		leftover = -leftover
+	}
+	for n := Money(0); n < leftover; n++ {
+		best := -1
+		for i, r := range remainders {
+			if r.value == nil {
+				continue
+			}
+			if best < 0 || r.value.Cmp(remainders[best].value) > 0 {
+				best = i
+			}
+		}
+		parts[remainders[best].index] += step
+		remainders[best].value = nil
+	}
+	return parts
+}
+
+// mulDivRound returns a × b / c rounded half away from zero without overflowing
+func mulDivRound(a, b, c int64) int64 {
+	if c == 0 {
+		return 0
+	}
+	if product := a * b; a == 0 || (product/a == b && product != -1<<63) {
+		return divRound(product, c)
+	}
+
+	product := new(big.Int).Mul(big.NewInt(a), big.NewInt(b))
+	quotient, rem := new(big.Int).QuoRem(product, big.NewInt(c), new(big.Int))
+	doubled := new(big.Int).Mul(new(big.Int).Abs(rem), big.NewInt(2))
+	if doubled.Cmp(new(big.Int).Abs(big.NewInt(c))) >= 0 {
+		if product.Sign()*big.NewInt(c).Sign() < 0 {
+			quotient.Sub(quotient, big.NewInt(1))
+		} else {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	}
+	return quotient.Int64()
+}
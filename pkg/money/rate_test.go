@@ -0,0 +1,149 @@
+package money
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Rate
+		wantErr bool
+	}{
+		{name: "whole percentage", input: "11", want: 1100},
+		{name: "fractional percentage", input: "5.5", want: 550},
+		{name: "zero", input: "0", want: 0},
+		{name: "empty means zero", input: "", want: 0},
+		{name: "percent sign", input: "12.5%", want: 1250},
+		{name: "third decimal rounds", input: "0.125", want: 13},
+		{name: "negative", input: "-1", wantErr: true},
+		{name: "invalid", input: "abc", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRate(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseRate(%q) expected an error", tt.input)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseRate(%q) unexpected error: %v", tt.input, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseRate(%q) = %d, want %d", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMulRate(t *testing.T) {
+	tests := []struct {
+		name   string
+		amount Money
+		rate   Rate
+		want   Money
+	}{
+		{name: "exact", amount: 10000, rate: 1100, want: 1100},
+		{name: "half rounds up", amount: 1, rate: 5000, want: 1},
+		{name: "rounds up", amount: 999, rate: 1100, want: 110},
+		{name: "negative rounds away from zero", amount: -999, rate: 1100, want: -110},
+		{name: "rounds down", amount: 12345, rate: 1250, want: 1543},
+		{name: "zero rate", amount: 12345, rate: 0, want: 0},
+		{name: "large amount does not overflow", amount: 1 << 62, rate: 5000, want: 1 << 61},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.amount.MulRate(tt.rate); got != tt.want {
+				t.Errorf("Money(%d).MulRate(%d) = %d, want %d", tt.amount, tt.rate, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRateOf(t *testing.T) {
+	tests := []struct {
+		name  string
+		part  Money
+		whole Money
+		want  Rate
+	}{
+		{name: "quarter", part: 250, whole: 1000, want: 2500},
+		{name: "one third rounds down", part: 1, whole: 3, want: 3333},
+		{name: "two thirds rounds up", part: 2, whole: 3, want: 6667},
+		{name: "zero whole", part: 100, whole: 0, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RateOf(tt.part, tt.whole); got != tt.want {
+				t.Errorf("RateOf(%d, %d) = %d, want %d", tt.part, tt.whole, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIncludedTax(t *testing.T) {
+	tests := []struct {
+		name   string
+		amount Money
+		rate   Rate
+		want   Money
+	}{
+		{name: "exact", amount: 11100, rate: 1100, want: 1100},
+		{name: "rounds up", amount: 10000, rate: 1100, want: 991},
+		{name: "rounds down", amount: 100, rate: 1000, want: 9},
+		{name: "negative rounds away from zero", amount: -10000, rate: 1100, want: -991},
+		{name: "zero rate", amount: 10000, rate: 0, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.amount.IncludedTax(tt.rate); got != tt.want {
+				t.Errorf("Money(%d).IncludedTax(%d) = %d, want %d", tt.amount, tt.rate, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllocate(t *testing.T) {
+	tests := []struct {
+		name    string
+		total   Money
+		weights []Money
+		want    []Money
+	}{
+		{name: "exact split", total: 1000, weights: []Money{500, 300, 200}, want: []Money{500, 300, 200}},
+		{name: "tie goes to the first line", total: 100, weights: []Money{1, 1, 1}, want: []Money{34, 33, 33}},
+		{name: "largest remainders first", total: 10, weights: []Money{1, 2, 4}, want: []Money{1, 3, 6}},
+		{name: "negative total", total: -100, weights: []Money{1, 1, 1}, want: []Money{-34, -33, -33}},
+		{name: "zero weights put everything on the first line", total: 10, weights: []Money{0, 0}, want: []Money{10, 0}},
+		{name: "zero total", total: 0, weights: []Money{3, 7}, want: []Money{0, 0}},
+		{name: "no weights", total: 10, weights: nil, want: []Money{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Allocate(tt.total, tt.weights)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Allocate(%d, %v) = %v, want %v", tt.total, tt.weights, got, tt.want)
+			}
+
+			if len(tt.weights) == 0 {
+				return
+			}
+			var sum Money
+			for _, part := range got {
+				sum += part
+			}
+			if sum != tt.total {
+				t.Errorf("Allocate(%d, %v) parts add up to %d", tt.total, tt.weights, sum)
+			}
+		})
+	}
+}
@@ -11,11 +11,12 @@ import (
 type JWTClaims struct {
 	UserID   uint   `json:"user_id"`
 	Username string `json:"username"`
+	Role     string `json:"role"`
 	jwt.RegisteredClaims
 }
 
 // GenerateToken creates a new JWT token for a user
-func GenerateToken(userID uint, username string, secret string) (string, error) {
+func GenerateToken(userID uint, username string, role string, secret string) (string, error) {
 	// Set token expiration to 24 hours
 	expirationTime := time.Now().Add(24 * time.Hour)
 
@@ -23,6 +24,7 @@ func GenerateToken(userID uint, username string, secret string) (string, error)
 	claims := &JWTClaims{
 		UserID:   userID,
 		Username: username,
+		Role:     role,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
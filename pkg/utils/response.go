@@ -7,10 +7,44 @@ import (
 )
 
 // Response represents a standard API response structure
+// Meta carries list metadata such as pagination and is omitted when not set
 type Response struct {
 	Success bool        `json:"success"`
 	Message string      `json:"message"`
 	Data    interface{} `json:"data"`
+	Meta    interface{} `json:"meta,omitempty"`
+}
+
+// PaginationMeta describes one page of a paginated list
+type PaginationMeta struct {
+	Page       int   `json:"page"`
+	Limit      int   `json:"limit"`
+	Total      int64 `json:"total"`
+	TotalPages int   `json:"total_pages"`
+}
+
+// NewPaginationMeta builds pagination metadata for a page of a list
+// limit is the page size and must be positive
+func NewPaginationMeta(page, limit int, total int64) PaginationMeta {
+	return PaginationMeta{
+		Page:       page,
+		Limit:      limit,
+		Total:      total,
+		TotalPages: int((total + int64(limit) - 1) / int64(limit)),
+	}
+}
+
+// CursorMeta describes one page of a cursor-paginated list
+// NextCursor is passed as the cursor of the following request and is null on the last page
+type CursorMeta struct {
+	Limit      int   `json:"limit"`
+	NextCursor *uint `json:"next_cursor"`
+	HasMore    bool  `json:"has_more"`
+}
+
+// NewCursorMeta builds cursor pagination metadata
+func NewCursorMeta(limit int, nextCursor *uint) CursorMeta {
+	return CursorMeta{Limit: limit, NextCursor: nextCursor, HasMore: nextCursor != nil}
 }
 
 // SuccessResponse sends a successful JSON response
@@ -22,6 +56,16 @@ func SuccessResponse(c *gin.Context, message string, data interface{}) {
 	})
 }
 
+// SuccessResponseWithMeta sends a successful JSON response with list metadata
+func SuccessResponseWithMeta(c *gin.Context, message string, data interface{}, meta interface{}) {
+	c.JSON(http.StatusOK, Response{
+		Success: true,
+		Message: message,
+		Data:    data,
+		Meta:    meta,
+	})
+}
+
 // ErrorResponse sends an error JSON response
 func ErrorResponse(c *gin.Context, statusCode int, message string) {
 	c.JSON(statusCode, Response{
@@ -59,3 +103,13 @@ func NotFoundResponse(c *gin.Context, message string) {
 func InternalServerErrorResponse(c *gin.Context, message string) {
 	ErrorResponse(c, http.StatusInternalServerError, message)
 }
+
+// ForbiddenResponse sends a 403 Forbidden error response
+func ForbiddenResponse(c *gin.Context, message string) {
+	ErrorResponse(c, http.StatusForbidden, message)
+}
+
+// ConflictResponse sends a 409 Conflict error response
+func ConflictResponse(c *gin.Context, message string) {
+	ErrorResponse(c, http.StatusConflict, message)
+}